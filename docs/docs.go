@@ -308,6 +308,9 @@ var doc = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "ret_code": {
                     "type": "integer"
                 }
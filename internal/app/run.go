@@ -29,20 +29,41 @@ var (
 	rootCmd    = &cobra.Command{
 		Short: projectName,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return run()
+			return run(apiserver.ServeModeAll)
 		},
 	}
+	serveCommand = &cobra.Command{
+		Use:   "serve",
+		Short: "Starts the service in the given mode.",
+	}
 	versionCommand = version.NewVerCommand(projectName)
 	envCommand     = apiserver.NewConfigEnvCommand(config.G)
+	configCommand  = apiserver.NewConfigCommand(&configFile, config.G)
 	initDB         = models.NewCreateDatabaseCommand(&configFile)
 )
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "configs/dev.yaml", "configuration file path")
-	rootCmd.AddCommand(versionCommand, envCommand, initDB)
+	conf.BindFlags(rootCmd.PersistentFlags())
+	serveCommand.AddCommand(
+		newServeCommand(apiserver.ServeModeAPI, "Starts the API server without background workers."),
+		newServeCommand(apiserver.ServeModeWorker, "Starts background workers without the API server."),
+		newServeCommand(apiserver.ServeModeAll, "Starts the API server and background workers."),
+	)
+	rootCmd.AddCommand(serveCommand, versionCommand, envCommand, configCommand, initDB)
+}
+
+func newServeCommand(mode, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   mode,
+		Short: short,
+		RunE: func(*cobra.Command, []string) error {
+			return run(mode)
+		},
+	}
 }
 
-func run() (err error) {
+func run(mode string) (err error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -55,21 +76,23 @@ func run() (err error) {
 
 	// 数据表迁移，新增表时修改 AllTables
 	m := apiserver.Migration(models.AllTables)
-	server := apiserver.CreateNewServer(ctx, config.G.APIConfig, handler.RegisterHandler, m)
+	// reload config.G on SIGHUP, file changes or POST /config/reload of the admin server,
+	// subscribe to your own sections by reloader.Subscribe("your_section", callback)
+	reloader := conf.NewReloader(configFile, config.G)
+	server, err := apiserver.NewServer(ctx, config.G.APIConfig, handler.RegisterHandler, m,
+		apiserver.ServeMode(mode), apiserver.ConfigReloader(reloader, "base"))
+	if err != nil {
+		logger.Errorf("server init failed: %s", err.Error())
+		return
+	}
 	defer server.Stop()
 
-	logger.Debugf("%+v", config.G)
+	logger.Debugf("%s", config.G)
 
-	// uncomment or delete code below as you need
+	// uncomment or delete code below as you need, workers are skipped by `serve api`
 	// producer.NewProducer(config.G.Kafka)
-	// c, err := kafka.Default().NewConsumer()
-	// if err != nil {
-	// 	logger.Fatal(err.Error())
-	// 	return
-	// }
-	// if err = consumer.RunConsume(c); err != nil {
-	// 	logger.Fatal(err.Error())
-	// 	return
+	// if err = server.AddWorker("kafka-consumer", consumer.Worker); err != nil {
+	// 	return err
 	// }
 
 	// 初始化 validator 翻译器
@@ -78,8 +101,7 @@ func run() (err error) {
 		return err
 	}
 
-	server.Start()
-	return err
+	return server.Run(ctx)
 }
 
 func Execute() {
@@ -14,14 +14,16 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"github.com/yvanz/gin-tmpl/pkg/middleware"
 )
 
 type BaseController struct{}
 
 type Response struct {
-	DataSet interface{} `json:"data_set"`
-	Message string      `json:"message"`
-	RetCode RetCode     `json:"ret_code"`
+	DataSet   interface{} `json:"data_set"`
+	Message   string      `json:"message"`
+	RequestID string      `json:"request_id,omitempty"`
+	RetCode   RetCode     `json:"ret_code"`
 }
 
 // CheckParams check params, params must be a pointer
@@ -57,7 +59,7 @@ func (c *BaseController) Response(ctx *gin.Context, data interface{}, err error)
 	var msg string
 	var retCode RetCode
 	if err != nil {
-		logger.Errorf("router: %s, method: %s, error: %s", ctx.Request.URL, ctx.Request.Method, err.Error())
+		logger.ErrorfWithTrace(ctx, "router: %s, method: %s, error: %s", ctx.Request.URL, ctx.Request.Method, err.Error())
 
 		switch e := err.(type) {
 		case *CodeWithErr:
@@ -66,6 +68,11 @@ func (c *BaseController) Response(ctx *gin.Context, data interface{}, err error)
 			retCode = FAILED
 		}
 
+		// the error is most likely caused by the deadline set by middleware.Timeout
+		if middleware.TimedOut(ctx) {
+			retCode = ErrorTimeout
+		}
+
 		msg = GetMsg(retCode)
 		msg = fmt.Sprintf("%s, %s", msg, err.Error())
 	} else {
@@ -76,5 +83,28 @@ func (c *BaseController) Response(ctx *gin.Context, data interface{}, err error)
 
 	jsonResponse.RetCode = retCode
 	jsonResponse.Message = msg
+	jsonResponse.RequestID = middleware.GetRequestID(ctx)
+	middleware.SetRetCode(ctx, int(retCode))
 	ctx.JSON(http.StatusOK, jsonResponse)
 }
+
+// AbortWithForbidden responds FORBIDDEN to requests failed in authentication, it is the onFailure of middleware.Auth
+func AbortWithForbidden(ctx *gin.Context, err error) {
+	var base BaseController
+	base.Response(ctx, nil, NewCodeWithErr(FORBIDDEN, err))
+	ctx.Abort()
+}
+
+// AbortWithTimeout responds ErrorTimeout to requests exceeding their budgets, it is the onTimeout of middleware.Timeout
+func AbortWithTimeout(ctx *gin.Context, err error) {
+	var base BaseController
+	base.Response(ctx, nil, NewCodeWithErr(ErrorTimeout, err))
+	ctx.Abort()
+}
+
+// AbortWithErrorPrivilege responds ErrorPrivilege to requests denied in authorization, it is the onDenied of middleware.Authorize
+func AbortWithErrorPrivilege(ctx *gin.Context, err error) {
+	var base BaseController
+	base.Response(ctx, nil, NewCodeWithErr(ErrorPrivilege, err))
+	ctx.Abort()
+}
@@ -21,6 +21,7 @@ const (
 	ErrorPrivilege
 	ErrorResourceNotExist
 	ErrorCallOtherSrv
+	ErrorTimeout
 )
 
 var codeMsg = map[RetCode]string{
@@ -35,6 +36,7 @@ var codeMsg = map[RetCode]string{
 	ErrorPrivilege:        "权限错误",
 	ErrorResourceNotExist: "资源不存在",
 	ErrorCallOtherSrv:     "调用第三方服务异常",
+	ErrorTimeout:          "请求超时",
 }
 
 func GetMsg(code RetCode) string {
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 
 	"github.com/yvanz/gin-tmpl/pkg/apiserver"
+	"github.com/yvanz/gin-tmpl/pkg/apiserver/conf"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
@@ -18,8 +19,9 @@ type Config struct {
 	apiserver.APIConfig `yaml:"base"`
 }
 
+// String returns the config in JSON with secrets masked
 func (c *Config) String() string {
-	configData, err := json.Marshal(c)
+	configData, err := json.Marshal(conf.Redact(c))
 	if err != nil {
 		logger.Error(err.Error())
 	}
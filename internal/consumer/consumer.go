@@ -35,6 +35,22 @@ func RunConsume(consumer *kafka.ConsumerClient) (err error) {
 	return nil
 }
 
+// Worker runs the consumer until ctx is done, it is meant to be registered by apiserver.Server.AddWorker
+func Worker(ctx context.Context) error {
+	consumer, err := kafka.Default().NewConsumer()
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	if err = RunConsume(consumer); err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
 type DemoMessages struct {
 	UserName string `json:"user_name"`
 }
@@ -42,14 +58,16 @@ type DemoMessages struct {
 func handler(message *sarama.ConsumerMessage) {
 	switch message.Topic {
 	case "test":
+		ctx := kafka.MessageContext(message)
+
 		var tmp DemoMessages
 		err := json.Unmarshal(message.Value, &tmp)
 		if err != nil {
-			logger.Errorf("Unmarshal %s of message offset %d with partition %d failed: %s", string(message.Value), message.Offset, message.Partition, err.Error())
+			logger.FromContext(ctx).Errorf("Unmarshal %s failed: %s", string(message.Value), err.Error())
 		} else {
-			err = consumerPurchase(tmp)
+			err = consumerPurchase(ctx, tmp)
 			if err != nil {
-				logger.Errorf("create data failed, message offset is %d with partition %d: %s", message.Offset, message.Partition, err.Error())
+				logger.FromContext(ctx).Errorf("create data failed: %s", err.Error())
 			}
 		}
 	default:
@@ -57,8 +75,8 @@ func handler(message *sarama.ConsumerMessage) {
 	}
 }
 
-func consumerPurchase(data DemoMessages) error {
-	db := gormdb.GetDB().Master(context.TODO())
+func consumerPurchase(ctx context.Context, data DemoMessages) error {
+	db := gormdb.GetDB().Master(ctx)
 	crud := gormdb.NewCRUD(db)
 
 	tmp := &models.Demo{
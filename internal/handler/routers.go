@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/opentracing/opentracing-go"
 	"github.com/yvanz/gin-tmpl/internal/common"
+	"github.com/yvanz/gin-tmpl/internal/config"
 	"github.com/yvanz/gin-tmpl/pkg/middleware"
 )
 
@@ -17,6 +18,14 @@ var base common.BaseController
 
 func RegisterHandler(tra opentracing.Tracer, engine *gin.Engine) {
 	apiGroup := engine.Group("/api")
+	apiGroup.Use(middleware.Timeout("api", common.AbortWithTimeout))
+	if config.G.Auth.Enabled() {
+		apiGroup.Use(middleware.Auth(common.AbortWithForbidden))
+	}
+	if config.G.RBAC.Enabled() {
+		apiGroup.Use(middleware.Authorize(common.AbortWithErrorPrivilege))
+	}
+	apiGroup.Use(middleware.RateLimit("api", nil))
 
 	if tra != nil {
 		apiGroup.Use(middleware.GinInterceptorWithTrace(tra, false))
@@ -92,7 +92,7 @@ func (s *Svc) Add(params AddParams) error {
 }
 
 func (s *Svc) KafkaMessage(params AddParams) error {
-	err := producer.SendMessage(params)
+	err := producer.SendMessage(s.Ctx, params)
 	if err != nil {
 		err = common.NewCodeWithErr(common.ErrorCallOtherSrv, err)
 	}
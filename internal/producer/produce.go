@@ -21,7 +21,7 @@ const (
 
 var kafkaProducer *kafka.AsyncProducer
 
-func SendMessage(msg interface{}, keys ...string) error {
+func SendMessage(ctx context.Context, msg interface{}, keys ...string) error {
 	if kafkaProducer == nil {
 		return fmt.Errorf("kakfa producer is not initialized yet")
 	}
@@ -34,7 +34,7 @@ func SendMessage(msg interface{}, keys ...string) error {
 
 	logger.Debugf("send message is %v", string(js))
 
-	return producer.Produce(TaskTopic, js, keys...)
+	return producer.ProduceWithContext(ctx, TaskTopic, js, keys...)
 }
 
 func NewProducer(conf kafka.Config) {
@@ -0,0 +1,145 @@
+/*
+@Date: 2026/10/18 15:40
+@Author: yvanz
+@File : builtin
+*/
+
+package apiserver
+
+import (
+	"context"
+
+	"github.com/yvanz/gin-tmpl/pkg/gormdb"
+	"github.com/yvanz/gin-tmpl/pkg/jwtauth"
+	"github.com/yvanz/gin-tmpl/pkg/kafka"
+	"github.com/yvanz/gin-tmpl/pkg/rbac"
+	"github.com/yvanz/gin-tmpl/pkg/rediscache"
+)
+
+const (
+	ComponentMySQL = "mysql"
+	ComponentRedis = "redis"
+	ComponentKafka = "kafka"
+	ComponentAuth  = "auth"
+	ComponentRBAC  = "rbac"
+)
+
+type mysqlComponent struct {
+	conf          gormdb.DBConfig
+	migrationList []interface{}
+}
+
+func (m *mysqlComponent) Name() string {
+	return ComponentMySQL
+}
+
+func (m *mysqlComponent) Start(ctx context.Context) error {
+	db, err := m.conf.BuildMySQLClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(m.migrationList) > 0 {
+		return db.Migration(m.migrationList...)
+	}
+
+	return nil
+}
+
+func (m *mysqlComponent) Stop(context.Context) error {
+	return gormdb.GetDB().Close()
+}
+
+func (m *mysqlComponent) Health(ctx context.Context) error {
+	return gormdb.GetDB().Ping(ctx)
+}
+
+type redisComponent struct {
+	conf rediscache.Config
+}
+
+func (r *redisComponent) Name() string {
+	return ComponentRedis
+}
+
+func (r *redisComponent) Start(ctx context.Context) error {
+	return r.conf.NewRedisCli(ctx)
+}
+
+func (r *redisComponent) Stop(context.Context) error {
+	return rediscache.Close()
+}
+
+func (r *redisComponent) Health(ctx context.Context) error {
+	return rediscache.Ping(ctx)
+}
+
+type kafkaComponent struct {
+	conf kafka.Config
+}
+
+func (k *kafkaComponent) Name() string {
+	return ComponentKafka
+}
+
+func (k *kafkaComponent) Start(ctx context.Context) error {
+	_, err := k.conf.BuildKafka(ctx)
+	return err
+}
+
+func (k *kafkaComponent) Stop(context.Context) error {
+	kafka.Default().Close()
+	return nil
+}
+
+func (k *kafkaComponent) Health(ctx context.Context) error {
+	return kafka.Default().Ping(ctx)
+}
+
+type authComponent struct {
+	conf jwtauth.Config
+}
+
+func (a *authComponent) Name() string {
+	return ComponentAuth
+}
+
+func (a *authComponent) Start(ctx context.Context) error {
+	return jwtauth.Init(ctx, a.conf)
+}
+
+func (a *authComponent) Stop(context.Context) error {
+	return nil
+}
+
+func (a *authComponent) Health(ctx context.Context) error {
+	return jwtauth.Default().Ping(ctx)
+}
+
+type rbacComponent struct {
+	conf rbac.Config
+}
+
+func (r *rbacComponent) Name() string {
+	return ComponentRBAC
+}
+
+func (r *rbacComponent) DependsOn() []string {
+	if r.conf.Table != "" {
+		return []string{ComponentMySQL}
+	}
+
+	return nil
+}
+
+func (r *rbacComponent) Start(ctx context.Context) error {
+	return rbac.Init(ctx, r.conf)
+}
+
+func (r *rbacComponent) Stop(context.Context) error {
+	return nil
+}
+
+func (r *rbacComponent) Health(context.Context) error {
+	return nil
+}
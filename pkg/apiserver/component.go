@@ -0,0 +1,175 @@
+/*
+@Date: 2026/10/18 15:02
+@Author: yvanz
+@File : component
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/yvanz/gin-tmpl/pkg/health"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+// Component is a dependency of the server, such as a DB pool or a cron runner.
+// Components are started in dependency order when the server is created
+// and stopped in reverse order when the server shuts down.
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	Health(ctx context.Context) error
+}
+
+// Dependent is implemented by components which must be started after other components
+type Dependent interface {
+	DependsOn() []string
+}
+
+type componentRegistry struct {
+	components []Component
+	started    []Component
+	lock       sync.Mutex
+}
+
+func (r *componentRegistry) add(c Component) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, exist := range r.components {
+		if exist.Name() == c.Name() {
+			return fmt.Errorf("component %s exists already", c.Name())
+		}
+	}
+
+	r.components = append(r.components, c)
+	return nil
+}
+
+// remove drops a component which is not started yet
+func (r *componentRegistry) remove(c Component) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.isStarted(c) {
+		return
+	}
+
+	for i, exist := range r.components {
+		if exist == c {
+			r.components = append(r.components[:i], r.components[i+1:]...)
+			return
+		}
+	}
+}
+
+// sorted returns the components in dependency order, keeping the registration order otherwise
+func (r *componentRegistry) sorted() ([]Component, error) {
+	byName := make(map[string]Component, len(r.components))
+	for _, c := range r.components {
+		byName[c.Name()] = c
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := make(map[string]int, len(r.components))
+	res := make([]Component, 0, len(r.components))
+
+	var visit func(c Component) error
+	visit = func(c Component) error {
+		switch state[c.Name()] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("circular dependency found on component %s", c.Name())
+		}
+
+		state[c.Name()] = visiting
+		if d, ok := c.(Dependent); ok {
+			for _, name := range d.DependsOn() {
+				dep, ok := byName[name]
+				if !ok {
+					return fmt.Errorf("component %s depends on %s which is not registered", c.Name(), name)
+				}
+
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[c.Name()] = visited
+
+		res = append(res, c)
+		return nil
+	}
+
+	for _, c := range r.components {
+		if err := visit(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// start starts the components which are not started yet and registers their health checks
+func (r *componentRegistry) start(ctx context.Context) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	components, err := r.sorted()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range components {
+		if r.isStarted(c) {
+			continue
+		}
+
+		logger.Debugf("starting component %s", c.Name())
+		if err = c.Start(ctx); err != nil {
+			return fmt.Errorf("start component %s failed: %s", c.Name(), err.Error())
+		}
+
+		r.started = append(r.started, c)
+		health.Register(c.Name(), c.Health)
+	}
+
+	return nil
+}
+
+func (r *componentRegistry) isStarted(c Component) bool {
+	for _, s := range r.started {
+		if s == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stop stops the started components in reverse order, it is safe to be called more than once
+func (r *componentRegistry) stop(ctx context.Context) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := len(r.started) - 1; i >= 0; i-- {
+		c := r.started[i]
+
+		logger.Debugf("stopping component %s", c.Name())
+		health.Unregister(c.Name())
+		if err := c.Stop(ctx); err != nil {
+			logger.Errorf("stop component %s failed: %s", c.Name(), err.Error())
+		}
+	}
+
+	r.started = nil
+}
@@ -0,0 +1,97 @@
+/*
+@Date: 2026/10/18 16:10
+@Author: yvanz
+@File : component_test
+*/
+
+package apiserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type fakeComponent struct {
+	events *[]string
+	name   string
+	deps   []string
+}
+
+func (f *fakeComponent) Name() string                 { return f.name }
+func (f *fakeComponent) DependsOn() []string          { return f.deps }
+func (f *fakeComponent) Health(context.Context) error { return nil }
+
+func (f *fakeComponent) Start(context.Context) error {
+	*f.events = append(*f.events, "start "+f.name)
+	return nil
+}
+
+func (f *fakeComponent) Stop(context.Context) error {
+	*f.events = append(*f.events, "stop "+f.name)
+	return nil
+}
+
+func TestComponentRegistry(t *testing.T) {
+	var events []string
+	r := componentRegistry{}
+
+	for _, c := range []*fakeComponent{
+		{name: "cron", deps: []string{"s3", "db"}},
+		{name: "s3"},
+		{name: "db"},
+	} {
+		c.events = &events
+		if err := r.add(c); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	if err := r.add(&fakeComponent{name: "db", events: &events}); err == nil {
+		t.Error("duplicated component should be rejected")
+	}
+
+	if err := r.start(context.Background()); err != nil {
+		t.Fatal(err.Error())
+	}
+	r.stop(context.Background())
+	r.stop(context.Background())
+
+	want := []string{"start s3", "start db", "start cron", "stop cron", "stop db", "stop s3"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("want %v, got %v", want, events)
+	}
+}
+
+func TestComponentDependencyError(t *testing.T) {
+	var events []string
+
+	tests := []struct {
+		name       string
+		components []*fakeComponent
+	}{
+		{name: "missing", components: []*fakeComponent{{name: "cron", deps: []string{"db"}}}},
+		{name: "circular", components: []*fakeComponent{
+			{name: "a", deps: []string{"b"}},
+			{name: "b", deps: []string{"a"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := componentRegistry{}
+			for _, c := range tt.components {
+				c.events = &events
+				_ = r.add(c)
+			}
+
+			if err := r.start(context.Background()); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+
+	if len(events) != 0 {
+		t.Errorf("nothing should be started, got %v", events)
+	}
+}
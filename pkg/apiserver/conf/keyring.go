@@ -0,0 +1,147 @@
+/*
+@Date: 2026/10/18 21:30
+@Author: yvanz
+@File : keyring
+*/
+
+package conf
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Keyring encrypts and decrypts config values with AES-GCM.
+// The key file has one key per line as "<id> <base64 key>", blank lines and lines starting with # are ignored.
+// The first key is the primary one used for encrypting, the others are kept for decrypting,
+// so that a key could be rotated by adding a new key on top and re-encrypting values at leisure.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	primary string
+}
+
+// LoadKeyring reads a Keyring from the key file
+func LoadKeyring(file string) (*Keyring, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD)}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("key file %s line %d: should be \"<id> <base64 key>\"", file, line)
+		}
+
+		id := fields[0]
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key file %s line %d: key id must not contain ':'", file, line)
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("key file %s line %d: duplicated key id %s", file, line, id)
+		}
+
+		key, e := base64.StdEncoding.DecodeString(fields[1])
+		if e != nil {
+			return nil, fmt.Errorf("key file %s line %d: %s", file, line, e.Error())
+		}
+
+		aead, e := newAEAD(key)
+		if e != nil {
+			return nil, fmt.Errorf("key file %s line %d: %s", file, line, e.Error())
+		}
+
+		k.keys[id] = aead
+		if k.primary == "" {
+			k.primary = id
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if k.primary == "" {
+		return nil, fmt.Errorf("no key found in key file %s", file)
+	}
+
+	return k, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// GenerateKey returns a line of a new random AES-256 key for the key file
+func GenerateKey(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, ": \t") {
+		return "", fmt.Errorf("invalid key id %q", id)
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+
+	return id + " " + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Encrypt encrypts plain with the primary key, the result is "enc:<key id>:<base64 nonce and cipher text>"
+func (k *Keyring) Encrypt(plain string) (string, error) {
+	aead := k.keys[k.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plain), []byte(k.primary))
+	return SecretEncPrefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt with the key it was encrypted by
+func (k *Keyring) Decrypt(value string) (string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, SecretEncPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("encrypted value should be enc:<key id>:<data>")
+	}
+
+	aead, ok := k.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("key %s is not found in the keyring", parts[0])
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted value is too short")
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("decrypt with key %s failed: %s", parts[0], err.Error())
+	}
+
+	return string(plain), nil
+}
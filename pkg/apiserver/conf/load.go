@@ -8,15 +8,323 @@ package conf
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
 )
 
+const (
+	sourceFlag    = "flag --set"
+	sourceDefault = "default"
+	sourceUnset   = "unset"
+)
+
+var setValues []string
+
+// Overlayer is implemented by configs which need an overlay file on top of the base file.
+// The overlay file is named after the returned value and placed beside the base file,
+// e.g. configs/prod.yaml for configs/dev.yaml when it returns "prod".
+type Overlayer interface {
+	Overlay() string
+}
+
+// Sources records where each effective value came from, keyed by the yaml path
+type Sources map[string]string
+
+func (s Sources) String() string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", k, s[k])
+	}
+
+	return b.String()
+}
+
+// BindFlags adds --set and --key-file to flags, values set by --set override the config loaded by LoadConfig
+func BindFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&setValues, "set", nil, "override a config value by its yaml path, e.g. --set base.mysql.max_open_conns=50")
+	flags.StringVar(&keyFile, "key-file", "", "key file to decrypt enc: values in config, "+KeyFileEnv+" is used if not set")
+}
+
+// Overrides returns the values bound by BindFlags
+func Overrides() []string {
+	return setValues
+}
+
+// LoadConfig loads c in layers: the base file, the overlay file, environment variables and --set flags,
+// then file: and enc: values of secret fields are resolved
 func LoadConfig(configFile string, c interface{}) error {
-	err := cleanenv.ReadConfig(configFile, c)
+	_, err := Load(configFile, c, setValues...)
+	return err
+}
+
+// Load is the same as LoadConfig, but takes overrides in "path=value" form explicitly
+// and reports where each effective value came from. c must be a pointer to a struct.
+func Load(configFile string, c interface{}, overrides ...string) (Sources, error) {
+	var layers []layer
+
+	ext := strings.ToLower(filepath.Ext(configFile))
+	if ext == ".yaml" || ext == ".yml" {
+		base, err := readLayer(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("read config file %s failed: %s", configFile, err.Error())
+		}
+		layers = append(layers, base)
+
+		if err = decodeLayers(layers, c); err != nil {
+			return nil, fmt.Errorf("read config file %s failed: %s", configFile, err.Error())
+		}
+
+		// the overlay is decided by the base file and environment variables
+		if o, ok := c.(Overlayer); ok && o.Overlay() != "" {
+			overlayFile := filepath.Join(filepath.Dir(configFile), o.Overlay()+ext)
+			if _, err = os.Stat(overlayFile); err == nil && filepath.Clean(overlayFile) != filepath.Clean(configFile) {
+				overlay, e := readLayer(overlayFile)
+				if e != nil {
+					return nil, fmt.Errorf("read overlay file %s failed: %s", overlayFile, e.Error())
+				}
+				layers = append(layers, overlay)
+
+				if err = decodeLayers(layers, c); err != nil {
+					return nil, fmt.Errorf("read overlay file %s failed: %s", overlayFile, err.Error())
+				}
+			}
+		}
+	} else if err := cleanenv.ReadConfig(configFile, c); err != nil {
+		// other formats are read as a single layer
+		return nil, fmt.Errorf("read config file %s failed: %s", configFile, err.Error())
+	}
+
+	sources := make(Sources)
+	collectSources(reflect.ValueOf(c).Elem(), "", layers, configFile, sources)
+
+	for _, o := range overrides {
+		kv := strings.SplitN(o, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid override %q, should be path=value", o)
+		}
+
+		path := strings.TrimSpace(kv[0])
+		if err := setByPath(c, path, kv[1]); err != nil {
+			return nil, fmt.Errorf("override %s failed: %s", path, err.Error())
+		}
+		sources[path] = sourceFlag
+	}
+
+	r := &secretResolver{}
+	if err := r.resolveSecrets(reflect.ValueOf(c).Elem(), ""); err != nil {
+		return nil, fmt.Errorf("resolve secrets failed: %s", err.Error())
+	}
+
+	return sources, nil
+}
+
+type layer struct {
+	data map[string]interface{}
+	name string
+}
+
+func readLayer(file string) (l layer, err error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	l.name = "file " + file
+	err = yaml.Unmarshal(content, &l.data)
+
+	return l, err
+}
+
+// decodeLayers resets c, decodes the merged layers into it and then reads environment variables
+func decodeLayers(layers []layer, c interface{}) error {
+	merged := make(map[string]interface{})
+	for _, l := range layers {
+		mergeMap(merged, l.data)
+	}
+
+	content, err := yaml.Marshal(merged)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	if err = yaml.Unmarshal(content, c); err != nil {
+		return err
+	}
+
+	return cleanenv.ReadEnv(c)
+}
+
+// mergeMap merges src into dst recursively, values in src win
+func mergeMap(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+
+		dstMap, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dstMap = make(map[string]interface{})
+			dst[k] = dstMap
+		}
+		mergeMap(dstMap, srcMap)
+	}
+}
+
+func yamlName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+
+	return name
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+func collectSources(v reflect.Value, prefix string, layers []layer, configFile string, sources Sources) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		path := joinPath(prefix, yamlName(f))
+		if f.Type.Kind() == reflect.Struct {
+			collectSources(v.Field(i), path, layers, configFile, sources)
+			continue
+		}
+
+		sources[path] = fieldSource(v.Field(i), f, path, layers, configFile)
+	}
+}
+
+func fieldSource(v reflect.Value, f reflect.StructField, path string, layers []layer, configFile string) string {
+	for _, env := range strings.Split(f.Tag.Get("env"), ",") {
+		if env == "" {
+			continue
+		}
+
+		if _, ok := os.LookupEnv(env); ok {
+			return "env " + env
+		}
+	}
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		if lookupPath(layers[i].data, path) {
+			return layers[i].name
+		}
+	}
+
+	if _, ok := f.Tag.Lookup("env-default"); ok {
+		return sourceDefault
+	}
+
+	// the file is not a yaml file, so we could only guess
+	if len(layers) == 0 && !v.IsZero() {
+		return "file " + configFile
+	}
+
+	return sourceUnset
+}
+
+func lookupPath(data map[string]interface{}, path string) bool {
+	keys := strings.Split(path, ".")
+	for i, k := range keys {
+		v, ok := data[k]
+		if !ok {
+			return false
+		}
+
+		if i == len(keys)-1 {
+			return true
+		}
+
+		if data, ok = v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+
+	return false
+}
+
+// lookupField finds the field of c by its yaml path, c itself is returned if path is empty
+func lookupField(c interface{}, path string) (reflect.Value, error) {
+	v := reflect.ValueOf(c).Elem()
+	if path == "" {
+		return v, nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		if v.Kind() != reflect.Struct {
+			return v, fmt.Errorf("%s is not a section", key)
+		}
+
+		found := false
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Tag.Get("yaml") == "-" || yamlName(f) != key {
+				continue
+			}
+
+			v = v.Field(i)
+			found = true
+			break
+		}
+
+		if !found {
+			return v, fmt.Errorf("unknown key %s", key)
+		}
+	}
+
+	return v, nil
+}
+
+// setByPath sets the field found by its yaml path, the value is parsed as yaml unless the field is a string
+func setByPath(c interface{}, path, value string) error {
+	v, err := lookupField(c, path)
 	if err != nil {
-		return fmt.Errorf("read config file %s failed: %s", configFile, err.Error())
+		return err
+	}
+
+	if v.Kind() == reflect.Struct {
+		return fmt.Errorf("%s is a section, only values could be set", path)
+	}
+
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+
+	field := reflect.New(v.Type())
+	if err = yaml.Unmarshal([]byte(value), field.Interface()); err != nil {
+		return err
 	}
+	v.Set(field.Elem())
 
 	return nil
 }
@@ -0,0 +1,117 @@
+/*
+@Date: 2026/10/18 19:50
+@Author: yvanz
+@File : load_test
+*/
+
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type layeredApp struct {
+	Name    string        `yaml:"name" env-default:"demo"`
+	Mode    string        `yaml:"run_mode" env:"TEST_LAYERED_RUN_MODE"`
+	Port    int           `yaml:"port" env:"TEST_LAYERED_PORT" env-default:"8000"`
+	Timeout time.Duration `yaml:"timeout" env-default:"5s"`
+}
+
+type layeredDB struct {
+	Host     string `yaml:"host"`
+	MaxConns int    `yaml:"max_open_conns"`
+	Logging  bool   `yaml:"logging"`
+}
+
+type layeredConfig struct {
+	App layeredApp `yaml:"app"`
+	DB  layeredDB  `yaml:"mysql"`
+}
+
+func (c *layeredConfig) Overlay() string {
+	return c.App.Mode
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "dev.yaml", `
+app:
+  run_mode: dev
+  port: 9901
+mysql:
+  host: 127.0.0.1
+  max_open_conns: 1000
+  logging: true
+`)
+	prod := writeFile(t, dir, "prod.yaml", `
+mysql:
+  host: db.prod
+  logging: false
+`)
+
+	os.Setenv("TEST_LAYERED_RUN_MODE", "prod")
+	os.Setenv("TEST_LAYERED_PORT", "80")
+	defer os.Unsetenv("TEST_LAYERED_RUN_MODE")
+	defer os.Unsetenv("TEST_LAYERED_PORT")
+
+	c := &layeredConfig{}
+	sources, err := Load(base, c, "mysql.max_open_conns=50", "app.timeout=1m")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := layeredConfig{
+		App: layeredApp{Name: "demo", Mode: "prod", Port: 80, Timeout: time.Minute},
+		DB:  layeredDB{Host: "db.prod", MaxConns: 50, Logging: false},
+	}
+	if *c != want {
+		t.Errorf("want %+v, got %+v", want, *c)
+	}
+
+	for path, source := range map[string]string{
+		"app.name":             sourceDefault,
+		"app.run_mode":         "env TEST_LAYERED_RUN_MODE",
+		"app.port":             "env TEST_LAYERED_PORT",
+		"app.timeout":          sourceFlag,
+		"mysql.host":           "file " + prod,
+		"mysql.logging":        "file " + prod,
+		"mysql.max_open_conns": sourceFlag,
+	} {
+		if sources[path] != source {
+			t.Errorf("%s: want source %q, got %q", path, source, sources[path])
+		}
+	}
+}
+
+func TestLoadWithoutOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "dev.yaml", "app:\n  run_mode: staging\nmysql:\n  host: 127.0.0.1\n")
+
+	c := &layeredConfig{}
+	sources, err := Load(base, c)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if c.DB.Host != "127.0.0.1" || sources["mysql.host"] != "file "+base || sources["mysql.max_open_conns"] != sourceUnset {
+		t.Errorf("unexpected config %+v or sources %v", *c, sources)
+	}
+
+	for _, o := range []string{"mysql", "mysql.port=1", "mysql.max_open_conns=many", "mysql.host.name=a"} {
+		if _, err = Load(base, &layeredConfig{}, o); err == nil {
+			t.Errorf("override %s should fail", o)
+		}
+	}
+}
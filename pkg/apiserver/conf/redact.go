@@ -0,0 +1,116 @@
+/*
+@Date: 2026/10/18 18:05
+@Author: yvanz
+@File : redact
+*/
+
+package conf
+
+import (
+	"reflect"
+)
+
+const (
+	// SecretTag marks a string field as secret, e.g. `secret:"true"`
+	SecretTag = "secret"
+	// SecretMask replaces the value of a non-empty secret field
+	SecretMask = "******"
+)
+
+// Redact returns a deep copy of c with all fields tagged by `secret:"true"` masked,
+// c itself is never modified. Empty secrets stay empty so that it is clear whether they are set.
+func Redact(c interface{}) interface{} {
+	if c == nil {
+		return nil
+	}
+
+	return redactValue(reflect.ValueOf(c)).Interface()
+}
+
+func redactValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.New(v.Elem().Type())
+		res.Elem().Set(redactValue(v.Elem()))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.New(v.Type()).Elem()
+		res.Set(redactValue(v.Elem()))
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := res.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+
+			if t.Field(i).Tag.Get(SecretTag) == "true" {
+				maskField(field)
+				continue
+			}
+
+			field.Set(redactValue(v.Field(i)))
+		}
+
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(redactValue(v.Index(i)))
+		}
+
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(iter.Key(), redactValue(iter.Value()))
+		}
+
+		return res
+	default:
+		return v
+	}
+}
+
+func maskField(field reflect.Value) {
+	switch field.Kind() {
+	case reflect.String:
+		if field.Len() > 0 {
+			field.SetString(SecretMask)
+		}
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String || field.IsNil() {
+			field.Set(reflect.Zero(field.Type()))
+			return
+		}
+
+		masked := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+		for i := 0; i < field.Len(); i++ {
+			masked.Index(i).SetString(SecretMask)
+		}
+		field.Set(masked)
+	default:
+		field.Set(reflect.Zero(field.Type()))
+	}
+}
@@ -0,0 +1,48 @@
+/*
+@Date: 2026/10/18 19:10
+@Author: yvanz
+@File : redact_test
+*/
+
+package conf
+
+import (
+	"testing"
+)
+
+type sentinel struct {
+	Password string   `secret:"true"`
+	Addrs    []string `secret:"false"`
+}
+
+type testConfig struct {
+	Sentinel *sentinel
+	Tokens   []string `secret:"true"`
+	Password string   `secret:"true"`
+	Empty    string   `secret:"true"`
+	Host     string
+}
+
+func TestRedact(t *testing.T) {
+	c := &testConfig{
+		Host:     "127.0.0.1",
+		Password: "root",
+		Tokens:   []string{"a", "b"},
+		Sentinel: &sentinel{Password: "sentinel", Addrs: []string{"127.0.0.1:26379"}},
+	}
+
+	r, ok := Redact(c).(*testConfig)
+	if !ok {
+		t.Fatalf("unexpected type %T", Redact(c))
+	}
+
+	if r.Password != SecretMask || r.Sentinel.Password != SecretMask || r.Tokens[1] != SecretMask {
+		t.Errorf("secrets are not masked: %+v %+v", r, r.Sentinel)
+	}
+	if r.Empty != "" || r.Host != c.Host || r.Sentinel.Addrs[0] != c.Sentinel.Addrs[0] {
+		t.Errorf("other fields should be kept: %+v", r)
+	}
+	if c.Password != "root" || c.Sentinel.Password != "sentinel" || c.Tokens[0] != "a" {
+		t.Error("the original config must not be modified")
+	}
+}
@@ -0,0 +1,186 @@
+/*
+@Date: 2026/10/18 20:30
+@Author: yvanz
+@File : reload
+*/
+
+package conf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+const (
+	// ReloadTag marks a field or a whole section which could not be changed without a restart, e.g. `reload:"restart"`
+	ReloadTag = "reload"
+	// ReloadRestart is the value of ReloadTag for restart-only fields
+	ReloadRestart = "restart"
+
+	watchDebounce = 500 * time.Millisecond
+)
+
+// ReloadFunc is called with copies of the old and new value of a section after it changes
+type ReloadFunc func(old, new interface{}) error
+
+type subscriber struct {
+	fn      ReloadFunc
+	section string
+}
+
+// Reloader reloads a config in place and notifies the subscribers of changed sections
+type Reloader struct {
+	current     interface{}
+	configFile  string
+	subscribers []subscriber
+	lock        sync.Mutex
+}
+
+// NewReloader returns a Reloader of c, c is the pointer loaded from configFile by LoadConfig
+func NewReloader(configFile string, c interface{}) *Reloader {
+	return &Reloader{configFile: configFile, current: c}
+}
+
+// Subscribe calls fn once the section changes, section is the yaml path such as "base.log",
+// an empty section means the whole config
+func (r *Reloader) Subscribe(section string, fn ReloadFunc) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, err := lookupField(r.current, section); err != nil {
+		return fmt.Errorf("subscribe %s failed: %s", section, err.Error())
+	}
+
+	r.subscribers = append(r.subscribers, subscriber{section: section, fn: fn})
+	return nil
+}
+
+// Reload loads the config again the same way as LoadConfig. The new config is rejected as a whole
+// if it is invalid or any restart-only field is changed, otherwise it replaces the current one
+// and the subscribers of changed sections are called in the order they subscribe.
+func (r *Reloader) Reload() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	fresh := reflect.New(reflect.TypeOf(r.current).Elem()).Interface()
+	if err := LoadConfig(r.configFile, fresh); err != nil {
+		return err
+	}
+
+	if v, ok := fresh.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("reload rejected: %s", err.Error())
+		}
+	}
+
+	var restart []string
+	restartChanges(reflect.ValueOf(r.current).Elem(), reflect.ValueOf(fresh).Elem(), "", &restart)
+	if len(restart) > 0 {
+		return fmt.Errorf("reload rejected, a restart is required to change %s", strings.Join(restart, ", "))
+	}
+
+	type change struct {
+		old, new interface{}
+		sub      subscriber
+	}
+
+	var changes []change
+	for _, sub := range r.subscribers {
+		oldV, _ := lookupField(r.current, sub.section)
+		newV, _ := lookupField(fresh, sub.section)
+		if reflect.DeepEqual(oldV.Interface(), newV.Interface()) {
+			continue
+		}
+
+		changes = append(changes, change{old: oldV.Interface(), new: newV.Interface(), sub: sub})
+	}
+
+	reflect.ValueOf(r.current).Elem().Set(reflect.ValueOf(fresh).Elem())
+
+	var errs []string
+	for _, c := range changes {
+		if err := c.sub.fn(c.old, c.new); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", c.sub.section, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("config reloaded, but some changes are not applied: %s", strings.Join(errs, "; "))
+	}
+
+	logger.Infof("config %s reloaded, %d subscriber(s) notified", r.configFile, len(changes))
+	return nil
+}
+
+// Watch reloads the config whenever a file in its directory changes until ctx is done.
+// The directory is watched rather than the file, so that overlays and replaced files are covered.
+func (r *Reloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err = watcher.Add(filepath.Dir(r.configFile)); err != nil {
+		return err
+	}
+
+	// editors and config maps usually produce several events for one change
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Op&fsnotify.Chmod != event.Op {
+				timer.Reset(watchDebounce)
+			}
+		case e, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			logger.Errorf("watch config %s failed: %s", r.configFile, e.Error())
+		case <-timer.C:
+			if e := r.Reload(); e != nil {
+				logger.Errorf("reload config %s failed: %s", r.configFile, e.Error())
+			}
+		}
+	}
+}
+
+func restartChanges(old, new reflect.Value, prefix string, changed *[]string) {
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		path := joinPath(prefix, yamlName(f))
+		if f.Tag.Get(ReloadTag) == ReloadRestart {
+			if !reflect.DeepEqual(old.Field(i).Interface(), new.Field(i).Interface()) {
+				*changed = append(*changed, path)
+			}
+			continue
+		}
+
+		if f.Type.Kind() == reflect.Struct {
+			restartChanges(old.Field(i), new.Field(i), path, changed)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+@Date: 2026/10/18 21:10
+@Author: yvanz
+@File : reload_test
+*/
+
+package conf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type reloadLog struct {
+	Level string `yaml:"level"`
+	Path  string `yaml:"log_path" reload:"restart"`
+}
+
+type reloadConfig struct {
+	Log  reloadLog `yaml:"log"`
+	Port int       `yaml:"port" reload:"restart"`
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "dev.yaml", "port: 80\nlog:\n  level: info\n  log_path: logs\n")
+
+	c := &reloadConfig{}
+	if err := LoadConfig(file, c); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	r := NewReloader(file, c)
+	if err := r.Subscribe("log.unknown", nil); err == nil {
+		t.Error("unknown section should be rejected")
+	}
+
+	changed := make(chan reloadLog, 1)
+	if err := r.Subscribe("log", func(_, new interface{}) error {
+		changed <- new.(reloadLog)
+		return nil
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := r.Reload(); err != nil || len(changed) != 0 {
+		t.Fatalf("nothing changed, but got %v or a notification", err)
+	}
+
+	writeFile(t, dir, "dev.yaml", "port: 8080\nlog:\n  level: debug\n  log_path: logs\n")
+	if err := r.Reload(); err == nil || c.Port != 80 || c.Log.Level != "info" {
+		t.Fatalf("changing port should be rejected as a whole, got %v and %+v", err, *c)
+	}
+
+	writeFile(t, dir, "dev.yaml", "port: 80\nlog:\n  level: debug\n  log_path: logs\n")
+	if err := r.Reload(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if got := <-changed; got.Level != "debug" || c.Log.Level != "debug" {
+		t.Errorf("want level debug, got %+v and %+v", got, *c)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = r.Watch(ctx)
+	}()
+
+	// give the watcher some time to start
+	time.Sleep(100 * time.Millisecond)
+	writeFile(t, dir, "dev.yaml", "port: 80\nlog:\n  level: warn\n  log_path: logs\n")
+
+	select {
+	case got := <-changed:
+		if got.Level != "warn" {
+			t.Errorf("want level warn, got %+v", got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("file changes are not reloaded")
+	}
+}
@@ -0,0 +1,118 @@
+/*
+@Date: 2026/10/18 21:40
+@Author: yvanz
+@File : secret
+*/
+
+package conf
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	// SecretFilePrefix makes a secret field read from a file, e.g. file:/run/secrets/db_pass
+	SecretFilePrefix = "file:"
+	// SecretEncPrefix makes a secret field decrypted by the keyring, see Keyring.Encrypt
+	SecretEncPrefix = "enc:"
+	// KeyFileEnv is the key file used when --key-file is not set
+	KeyFileEnv = "CONFIG_KEY_FILE"
+)
+
+var keyFile string
+
+// KeyFile returns the key file set by --key-file or KeyFileEnv
+func KeyFile() string {
+	if keyFile != "" {
+		return keyFile
+	}
+
+	return os.Getenv(KeyFileEnv)
+}
+
+type secretResolver struct {
+	keyring *Keyring
+}
+
+func (r *secretResolver) resolve(path, value string) (string, error) {
+	if strings.HasPrefix(value, SecretFilePrefix) {
+		content, err := os.ReadFile(strings.TrimPrefix(value, SecretFilePrefix))
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", path, err.Error())
+		}
+
+		// the file itself could hold an encrypted value
+		value = strings.TrimRight(string(content), "\r\n")
+	}
+
+	if !strings.HasPrefix(value, SecretEncPrefix) {
+		return value, nil
+	}
+
+	if r.keyring == nil {
+		file := KeyFile()
+		if file == "" {
+			return "", fmt.Errorf("%s is encrypted, but no key file is set by --key-file or %s", path, KeyFileEnv)
+		}
+
+		keyring, err := LoadKeyring(file)
+		if err != nil {
+			return "", err
+		}
+		r.keyring = keyring
+	}
+
+	plain, err := r.keyring.Decrypt(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	return plain, nil
+}
+
+// resolveSecrets replaces file: and enc: values of the fields tagged by `secret:"true"` with what they refer to
+func (r *secretResolver) resolveSecrets(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		path := joinPath(prefix, yamlName(f))
+		field := v.Field(i)
+
+		if f.Type.Kind() == reflect.Struct {
+			if err := r.resolveSecrets(field, path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if f.Tag.Get(SecretTag) != "true" {
+			continue
+		}
+
+		switch {
+		case field.Kind() == reflect.String:
+			value, err := r.resolve(path, field.String())
+			if err != nil {
+				return err
+			}
+			field.SetString(value)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+			for j := 0; j < field.Len(); j++ {
+				value, err := r.resolve(fmt.Sprintf("%s[%d]", path, j), field.Index(j).String())
+				if err != nil {
+					return err
+				}
+				field.Index(j).SetString(value)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+@Date: 2026/10/18 21:55
+@Author: yvanz
+@File : secret_test
+*/
+
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type secretDB struct {
+	Password string   `yaml:"password" secret:"true"`
+	Tokens   []string `yaml:"tokens" secret:"true"`
+	Host     string   `yaml:"host"`
+}
+
+type secretConfig struct {
+	DB secretDB `yaml:"db"`
+}
+
+func TestKeyringRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	oldKey, err := GenerateKey("k1")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	keyFile := writeFile(t, dir, "keyring", oldKey+"\n")
+
+	k1, err := LoadKeyring(keyFile)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	encrypted, err := k1.Encrypt("root")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.HasPrefix(encrypted, SecretEncPrefix+"k1:") {
+		t.Errorf("unexpected encrypted value %s", encrypted)
+	}
+
+	// rotate: the new key becomes the primary one, the old one still decrypts
+	newKey, _ := GenerateKey("k2")
+	writeFile(t, dir, "keyring", "# rotated\n"+newKey+"\n"+oldKey+"\n")
+	k2, err := LoadKeyring(keyFile)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if plain, e := k2.Decrypt(encrypted); e != nil || plain != "root" {
+		t.Errorf("want root, got %q and %v", plain, e)
+	}
+	if reEncrypted, _ := k2.Encrypt("root"); !strings.HasPrefix(reEncrypted, SecretEncPrefix+"k2:") {
+		t.Errorf("value should be encrypted by the new primary key, got %s", reEncrypted)
+	}
+
+	tampered := encrypted[:len(encrypted)-4] + "AAA="
+	if _, err = k2.Decrypt(tampered); err == nil {
+		t.Error("tampered value should not be decrypted")
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+
+	key, _ := GenerateKey("k1")
+	keyFile := writeFile(t, dir, "keyring", key)
+	k, _ := LoadKeyring(keyFile)
+	token, _ := k.Encrypt("token")
+
+	secretFile := writeFile(t, dir, "db_pass", "from-file\n")
+	config := writeFile(t, dir, "dev.yaml", "db:\n  password: file:"+secretFile+"\n  host: file:not-a-secret\n  tokens:\n    - "+token+"\n")
+
+	c := &secretConfig{}
+	if _, err := Load(config, c); err == nil {
+		t.Error("encrypted values need a key file")
+	}
+
+	os.Setenv(KeyFileEnv, keyFile)
+	defer os.Unsetenv(KeyFileEnv)
+
+	if _, err := Load(config, c); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := secretDB{Password: "from-file", Tokens: []string{"token"}, Host: "file:not-a-secret"}
+	if c.DB.Password != want.Password || c.DB.Tokens[0] != want.Tokens[0] || c.DB.Host != want.Host {
+		t.Errorf("want %+v, got %+v", want, c.DB)
+	}
+}
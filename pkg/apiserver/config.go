@@ -7,17 +7,25 @@
 package apiserver
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/spf13/cobra"
+	"github.com/yvanz/gin-tmpl/pkg/apiserver/conf"
 	"github.com/yvanz/gin-tmpl/pkg/gormdb"
+	"github.com/yvanz/gin-tmpl/pkg/jwtauth"
 	"github.com/yvanz/gin-tmpl/pkg/kafka"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"github.com/yvanz/gin-tmpl/pkg/middleware"
+	"github.com/yvanz/gin-tmpl/pkg/ratelimit"
+	"github.com/yvanz/gin-tmpl/pkg/rbac"
 	"github.com/yvanz/gin-tmpl/pkg/rediscache"
 	"github.com/yvanz/gin-tmpl/pkg/tracer"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -33,19 +41,53 @@ type APIConfig struct { //nolint:govet
 	App    AppConfig         `yaml:"app" json:"app,omitempty"`
 	Log    logger.Config     `yaml:"log" json:"log,omitempty"`
 	MySQL  gormdb.DBConfig   `yaml:"mysql" json:"mysql,omitempty"`
-	Redis  rediscache.Config `yaml:"redis" json:"redis,omitempty"`
-	Kafka  kafka.Config      `yaml:"kafka" json:"kafka,omitempty"`
+	Redis  rediscache.Config `yaml:"redis" json:"redis,omitempty" reload:"restart"`
+	Kafka  kafka.Config      `yaml:"kafka" json:"kafka,omitempty" reload:"restart"`
 	Tracer tracer.Config     `yaml:"tracer" json:"tracer,omitempty"`
+	Auth   jwtauth.Config    `yaml:"auth" json:"auth,omitempty" reload:"restart"`
+	RBAC   rbac.Config       `yaml:"rbac" json:"rbac,omitempty"`
+	// RateLimit configures the limiters used by middleware.RateLimit by name
+	RateLimit map[string]ratelimit.Config `yaml:"rate_limit" json:"rate_limit,omitempty"`
+	// Timeout configures the budgets used by middleware.Timeout by name
+	Timeout map[string]time.Duration `yaml:"timeout" json:"timeout,omitempty"`
 }
 
 type AppConfig struct {
-	ServiceName string `yaml:"service_name" env-default:"gin-project" env-description:"the name of the service" json:"service_name,omitempty"`
-	HostIP      string `yaml:"local_ip" env:"HostIP" env-default:"0.0.0.0" env-description:"listening on which IP" json:"host_ip,omitempty"`
-	RunMode     string `yaml:"run_mode" env:"RunMode" env-description:"run mode of the service" json:"run_mode,omitempty"`
-	CertFile    string `yaml:"cert_file" env:"CertFile" env-description:"cert file if server need to use tls" json:"cert_file,omitempty"`
-	KeyFile     string `yaml:"key_file" env:"KeyFile" env-description:"key file if server need to use tls" json:"key_file,omitempty"`
-	APIPort     int    `yaml:"api_port" env:"APIPort" env-default:"8000" env-description:"listening on which port" json:"api_port,omitempty"`
-	AdminPort   int    `yaml:"admin_port" env:"AdminPort" env-default:"8001" env-description:"listening on which port of admin service" json:"admin_port,omitempty"`
+	CORS             middleware.CorsConfig       `yaml:"cors" json:"cors,omitempty"`
+	RequestLog       middleware.RequestLogConfig `yaml:"request_log" json:"request_log,omitempty"`
+	AccessLog        middleware.AccessLogConfig  `yaml:"access_log" json:"access_log,omitempty"`
+	HTTP             HTTPConfig                  `yaml:"http" json:"http,omitempty" reload:"restart"`
+	Engines          []EngineConfig              `yaml:"engines" json:"engines,omitempty" reload:"restart"`
+	CipherSuites     []string                    `yaml:"cipher_suites" env:"TLSCipherSuites" env-description:"cipher suites for TLS 1.0-1.2, defaults of Go are used if empty" json:"cipher_suites,omitempty" reload:"restart"`
+	ServiceName      string                      `yaml:"service_name" env-default:"gin-project" env-description:"the name of the service" json:"service_name,omitempty" reload:"restart"`
+	HostIP           string                      `yaml:"local_ip" env:"HostIP" env-default:"0.0.0.0" env-description:"listening on which IP" json:"host_ip,omitempty" reload:"restart"`
+	RunMode          string                      `yaml:"run_mode" env:"RunMode" env-description:"run mode of the service" json:"run_mode,omitempty" reload:"restart"`
+	CertFile         string                      `yaml:"cert_file" env:"CertFile" env-description:"cert file if server need to use tls" json:"cert_file,omitempty" reload:"restart"`
+	KeyFile          string                      `yaml:"key_file" env:"KeyFile" env-description:"key file if server need to use tls" json:"key_file,omitempty" reload:"restart"`
+	ClientCAFile     string                      `yaml:"client_ca_file" env:"ClientCAFile" env-description:"CA bundle to verify client certificates" json:"client_ca_file,omitempty" reload:"restart"`
+	ClientAuth       string                      `yaml:"client_auth" env:"ClientAuth" env-default:"none" env-description:"client certificate verification: none/request/require-and-verify" json:"client_auth,omitempty" reload:"restart"`
+	TLSMinVersion    string                      `yaml:"tls_min_version" env:"TLSMinVersion" env-default:"1.2" env-description:"minimum TLS version: 1.0/1.1/1.2/1.3" json:"tls_min_version,omitempty" reload:"restart"`
+	APIListen        string                      `yaml:"api_listen" env:"APIListen" env-description:"listen address of the API server instead of local_ip and api_port: host:port, unix:///path or fd://3" json:"api_listen,omitempty" reload:"restart"`
+	AdminListen      string                      `yaml:"admin_listen" env:"AdminListen" env-description:"listen address of the admin server instead of local_ip and admin_port: host:port, unix:///path or fd://3" json:"admin_listen,omitempty" reload:"restart"`
+	GRPCListen       string                      `yaml:"grpc_listen" env:"GRPCListen" env-description:"listen address of the gRPC server instead of local_ip and grpc_port: host:port, unix:///path or fd://3" json:"grpc_listen,omitempty" reload:"restart"`
+	UnixSocketMode   string                      `yaml:"unix_socket_mode" env:"UnixSocketMode" env-default:"0660" env-description:"permissions of unix sockets in octal" json:"unix_socket_mode,omitempty" reload:"restart"`
+	APIPort          int                         `yaml:"api_port" env:"APIPort" env-default:"8000" env-description:"listening on which port" json:"api_port,omitempty" reload:"restart"`
+	AdminPort        int                         `yaml:"admin_port" env:"AdminPort" env-default:"8001" env-description:"listening on which port of admin service" json:"admin_port,omitempty" reload:"restart"`
+	GRPCPort         int                         `yaml:"grpc_port" env:"GRPCPort" env-description:"listening on which port of gRPC service, disabled if neither grpc_port nor grpc_listen is set" json:"grpc_port,omitempty" reload:"restart"`
+	DrainTimeout     time.Duration               `yaml:"drain_timeout" env:"DrainTimeout" env-default:"5s" env-description:"how long to wait for in-flight requests of each server when shutting down" json:"drain_timeout,omitempty" reload:"restart"`
+	UpgradeTimeout   time.Duration               `yaml:"upgrade_timeout" env:"UpgradeTimeout" env-default:"30s" env-description:"how long to wait for the new process to be ready on graceful upgrade" json:"upgrade_timeout,omitempty" reload:"restart"`
+	ForceQuitTimeout time.Duration               `yaml:"force_quit_timeout" env:"ForceQuitTimeout" env-default:"10s" env-description:"force quit if shutting down takes longer than this" json:"force_quit_timeout,omitempty" reload:"restart"`
+	GracefulUpgrade  bool                        `yaml:"graceful_upgrade" env:"GracefulUpgrade" env-description:"upgrade to a new binary without downtime on SIGUSR2 or POST /upgrade of the admin server" json:"graceful_upgrade,omitempty" reload:"restart"`
+}
+
+// grpcEnabled reports whether the gRPC server should be started
+func (c AppConfig) grpcEnabled() bool {
+	return c.GRPCPort != 0 || c.GRPCListen != ""
+}
+
+// Overlay makes the config file of the run mode an overlay of the base config file
+func (c *APIConfig) Overlay() string {
+	return c.App.RunMode
 }
 
 func (c *APIConfig) buildLogger() *logger.DemoLog {
@@ -56,8 +98,9 @@ func (c *APIConfig) buildLogger() *logger.DemoLog {
 	return logger.ConfigureLogger(&logger.Options{Config: c.Log})
 }
 
+// String returns the config in JSON with secrets masked
 func (c *APIConfig) String() string {
-	configData, err := json.Marshal(c)
+	configData, err := json.Marshal(conf.Redact(c))
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -65,38 +108,31 @@ func (c *APIConfig) String() string {
 	return string(configData)
 }
 
-func (c *APIConfig) initService(ctx context.Context, opts *serverOptions) (err error) {
+// builtinComponents returns the clients enabled by the config
+func (c *APIConfig) builtinComponents(opts *serverOptions) []Component {
+	var components []Component
 	if c.MySQL.WriteDBHost != "" {
 		c.MySQL.RawColumn = opts.tableColumnWithRaw
-		db, e := c.MySQL.BuildMySQLClient(ctx)
-		if e != nil {
-			err = e
-			return
-		}
-
-		if len(opts.migrationList) > 0 {
-			err = db.Migration(opts.migrationList...)
-			if err != nil {
-				return
-			}
-		}
+		components = append(components, &mysqlComponent{conf: c.MySQL, migrationList: opts.migrationList})
 	}
 
 	if c.Redis.Addr != "" {
-		err = c.Redis.NewRedisCli(ctx)
-		if err != nil {
-			return
-		}
+		components = append(components, &redisComponent{conf: c.Redis})
 	}
 
 	if c.Kafka.Addr != "" {
-		_, err = c.Kafka.BuildKafka(ctx)
-		if err != nil {
-			return
-		}
+		components = append(components, &kafkaComponent{conf: c.Kafka})
+	}
+
+	if c.Auth.Enabled() {
+		components = append(components, &authComponent{conf: c.Auth})
+	}
+
+	if c.RBAC.Enabled() {
+		components = append(components, &rbacComponent{conf: c.RBAC})
 	}
 
-	return err
+	return components
 }
 
 func NewConfigEnvCommand(c interface{}) *cobra.Command {
@@ -109,3 +145,126 @@ func NewConfigEnvCommand(c interface{}) *cobra.Command {
 		},
 	}
 }
+
+// NewConfigCommand returns the config command with subcommands print, validate, sources, encrypt and keygen.
+// c must be a pointer, it is validated by its Validate method if it has one.
+func NewConfigCommand(configFile *string, c interface{}) *cobra.Command {
+	var format string
+
+	printCmd := &cobra.Command{
+		Use:   "print",
+		Short: "Prints the effective config with secrets masked.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := conf.LoadConfig(*configFile, c); err != nil {
+				return err
+			}
+
+			var out []byte
+			var err error
+			switch format {
+			case "yaml":
+				out, err = yaml.Marshal(conf.Redact(c))
+			case "json":
+				out, err = json.MarshalIndent(conf.Redact(c), "", "  ")
+				out = append(out, '\n')
+			default:
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+	printCmd.Flags().StringVarP(&format, "output", "o", "yaml", "output format, yaml or json")
+
+	validateCmd := &cobra.Command{
+		Use:          "validate",
+		Short:        "Validates the config and lists every problem found.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := conf.LoadConfig(*configFile, c); err != nil {
+				return err
+			}
+
+			if v, ok := c.(interface{ Validate() error }); ok {
+				if err := v.Validate(); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "config file %s is valid\n", *configFile)
+			return nil
+		},
+	}
+
+	sourcesCmd := &cobra.Command{
+		Use:   "sources",
+		Short: "Prints where each effective config value came from.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			sources, err := conf.Load(*configFile, c, conf.Overrides()...)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprint(cmd.OutOrStdout(), sources.String())
+			return err
+		},
+	}
+
+	encryptCmd := &cobra.Command{
+		Use:   "encrypt [value]",
+		Short: "Encrypts a value with the primary key of --key-file for pasting into config, reads stdin if no value given.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyring, err := conf.LoadKeyring(conf.KeyFile())
+			if err != nil {
+				return err
+			}
+
+			var value string
+			if len(args) > 0 {
+				value = args[0]
+			} else {
+				content, e := io.ReadAll(cmd.InOrStdin())
+				if e != nil {
+					return e
+				}
+				value = strings.TrimRight(string(content), "\r\n")
+			}
+
+			encrypted, err := keyring.Encrypt(value)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), encrypted)
+			return err
+		},
+	}
+
+	keygenCmd := &cobra.Command{
+		Use:   "keygen <id>",
+		Short: "Generates a key line, put it on top of the key file to make it the primary key.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			line, err := conf.GenerateKey(args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), line)
+			return err
+		},
+	}
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Prints, validates or encrypts the config.",
+	}
+	configCmd.AddCommand(printCmd, validateCmd, sourcesCmd, encryptCmd, keygenCmd)
+
+	return configCmd
+}
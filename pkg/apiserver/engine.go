@@ -0,0 +1,175 @@
+/*
+@Date: 2026/10/19 11:00
+@Author: yvanz
+@File : engine
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/yvanz/gin-tmpl/pkg/middleware"
+)
+
+var engineNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// EngineConfig is a named engine listening on its own address along with the API engine,
+// e.g. an internal engine for service-to-service endpoints which is not reachable from the public ingress.
+// Its routes and middleware are registered by the Engine option with the same name.
+type EngineConfig struct {
+	Name         string `yaml:"name" json:"name,omitempty"`
+	Listen       string `yaml:"listen" json:"listen,omitempty"`
+	HostIP       string `yaml:"local_ip" json:"host_ip,omitempty"`
+	CertFile     string `yaml:"cert_file" json:"cert_file,omitempty"`
+	KeyFile      string `yaml:"key_file" json:"key_file,omitempty"`
+	ClientCAFile string `yaml:"client_ca_file" json:"client_ca_file,omitempty"`
+	ClientAuth   string `yaml:"client_auth" json:"client_auth,omitempty"`
+	Port         int    `yaml:"port" json:"port,omitempty"`
+}
+
+// app returns app with the listen address and TLS files of the engine,
+// local_ip, tls_min_version, cipher_suites, unix_socket_mode and http are inherited from app
+func (e EngineConfig) app(app AppConfig) AppConfig {
+	if e.HostIP != "" {
+		app.HostIP = e.HostIP
+	}
+
+	app.APIListen = e.Listen
+	app.APIPort = e.Port
+	app.CertFile = e.CertFile
+	app.KeyFile = e.KeyFile
+	app.ClientCAFile = e.ClientCAFile
+	app.ClientAuth = e.ClientAuth
+
+	return app
+}
+
+type namedEngine struct {
+	engine *gin.Engine
+	conf   EngineConfig
+}
+
+func (s *Server) initEngines(registers map[string]func(opentracing.Tracer, *gin.Engine)) error {
+	configured := make(map[string]bool, len(s.conf.App.Engines))
+	for _, e := range s.conf.App.Engines {
+		register, ok := registers[e.Name]
+		if !ok {
+			return fmt.Errorf("engine %s is configured but not registered by apiserver.Engine", e.Name)
+		}
+		configured[e.Name] = true
+
+		g := gin.New()
+		g.Use(middleware.RequestID(), middleware.Metrics(), gin.Recovery(), middleware.AccessLog())
+		if e.ClientCAFile != "" {
+			g.Use(middleware.ClientCert())
+		}
+		register(s.GetTracer(), g)
+
+		s.engines = append(s.engines, namedEngine{engine: g, conf: e})
+	}
+
+	for name := range registers {
+		if !configured[name] {
+			s.logger.Warnf("engine %s is not configured in app.engines, skipped", name)
+		}
+	}
+
+	return nil
+}
+
+// engineServer returns the server of handler, listening on the api_listen or api_port of c
+func (s *Server) engineServer(name string, c AppConfig, handler http.Handler) (*httpServer, error) {
+	socketMode, err := parseSocketMode(c.UnixSocketMode)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := listenAddr(c.APIListen, c.HostIP, c.APIPort)
+	if c.CertFile == "" || c.KeyFile == "" {
+		srv := newHTTPServer(name, addr, handler, nil)
+		srv.socketMode = socketMode
+
+		return srv, c.HTTP.apply(srv.srv, c.HTTP.H2C)
+	}
+
+	tlsConfig, certs, err := newTLSConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := newHTTPServer(name, addr, handler, func(srv *http.Server, l net.Listener) error {
+		stop := certs.watch()
+		defer stop()
+
+		return srv.ServeTLS(l, "", "")
+	})
+	srv.srv.TLSConfig = tlsConfig
+	srv.socketMode = socketMode
+
+	return srv, c.HTTP.apply(srv.srv, false)
+}
+
+// validateEngines checks the engines along with the ports and listen addresses of the built-in servers
+func (c AppConfig) validateEngines() (errs []error) {
+	names := map[string]bool{"api": true, "admin": true, "grpc": true}
+	ports := map[int]bool{c.APIPort: true, c.AdminPort: true, c.GRPCPort: true}
+	listens := map[string]bool{c.APIListen: true, c.AdminListen: true, c.GRPCListen: true}
+
+	for i, e := range c.Engines {
+		prefix := fmt.Sprintf("engines[%d]", i)
+		if e.Name != "" {
+			prefix = "engine " + e.Name
+		}
+
+		switch {
+		case !engineNameRegexp.MatchString(e.Name):
+			errs = append(errs, fmt.Errorf("%s: name %q should match %s", prefix, e.Name, engineNameRegexp))
+		case names[e.Name]:
+			errs = append(errs, fmt.Errorf("%s: name is used already", prefix))
+		}
+		names[e.Name] = true
+
+		if e.Listen != "" {
+			if err := validateListenAddr(e.Listen); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s", prefix, err.Error()))
+			}
+			if listens[e.Listen] {
+				errs = append(errs, fmt.Errorf("%s: listen %s is used already", prefix, e.Listen))
+			}
+			listens[e.Listen] = true
+		} else {
+			if e.Port < 1 || e.Port > 65535 {
+				errs = append(errs, fmt.Errorf("%s: port %d is out of range 1-65535", prefix, e.Port))
+			}
+			if ports[e.Port] {
+				errs = append(errs, fmt.Errorf("%s: port %d is used already", prefix, e.Port))
+			}
+			ports[e.Port] = true
+		}
+
+		if (e.CertFile == "") != (e.KeyFile == "") {
+			errs = append(errs, fmt.Errorf("%s: cert_file and key_file must be set together", prefix))
+		}
+		for _, err := range e.app(c).validateTLS() {
+			errs = append(errs, fmt.Errorf("%s: %s", prefix, err.Error()))
+		}
+		for _, f := range []string{e.CertFile, e.KeyFile, e.ClientCAFile} {
+			if f == "" {
+				continue
+			}
+
+			if _, err := os.Stat(f); err != nil {
+				errs = append(errs, fmt.Errorf("%s: tls file: %s", prefix, err.Error()))
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,58 @@
+/*
+@Date: 2026/10/19 11:40
+@Author: yvanz
+@File : engine_test
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+)
+
+func TestNamedEngines(t *testing.T) {
+	c := testConfig(t)
+	c.App.Engines = []EngineConfig{{Name: "internal", Port: freePort(t)}}
+
+	if _, err := NewServer(context.Background(), c, nil); err == nil {
+		t.Error("engines without register callbacks should be rejected")
+	}
+
+	startServer(t, c, nil, Engine("internal", func(_ opentracing.Tracer, g *gin.Engine) {
+		g.GET("/internal", func(c *gin.Context) {
+			c.String(http.StatusOK, "internal")
+		})
+	}))
+
+	resp := waitForServer(t, fmt.Sprintf("http://127.0.0.1:%d/internal", c.App.Engines[0].Port))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("internal engine: want 200, got %d", resp.StatusCode)
+	}
+
+	resp = waitForServer(t, fmt.Sprintf("http://127.0.0.1:%d/internal", c.App.APIPort))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("routes of the internal engine should not be served by the API engine, got %d", resp.StatusCode)
+	}
+}
+
+func TestValidateEngines(t *testing.T) {
+	c := AppConfig{APIPort: 8000, AdminPort: 8001, Engines: []EngineConfig{
+		{Name: "admin", Port: 9000},
+		{Name: "Internal", Port: 8000},
+		{Name: "internal", Listen: "unix://", CertFile: "cert.pem"},
+	}}
+
+	// admin is reserved, Internal is invalid and 8000 is used,
+	// the path of the socket is missing and the key and the cert file are missing
+	if errs := c.validateEngines(); len(errs) != 6 {
+		t.Errorf("want 6 problems, got %v", errs)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+@Date: 2026/10/19 10:20
+@Author: yvanz
+@File : grpc_test
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCServer(t *testing.T) {
+	c := testConfig(t)
+	c.App.GRPCPort = freePort(t)
+	server := startServer(t, c, nil)
+
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer dialCancel()
+	conn, err := grpc.DialContext(dialCtx, fmt.Sprintf("127.0.0.1:%d", c.App.GRPCPort), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	client := healthpb.NewHealthClient(conn)
+	var resp *healthpb.HealthCheckResponse
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err == nil && resp.Status == healthpb.HealthCheckResponse_SERVING {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("want SERVING, got %v, %v", resp, err)
+	}
+
+	if err = server.stop(); err != nil {
+		t.Errorf("run should stop gracefully: %s", err.Error())
+	}
+}
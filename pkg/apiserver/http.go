@@ -0,0 +1,80 @@
+/*
+@Date: 2026/10/19 00:40
+@Author: yvanz
+@File : http
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTPConfig tunes the http.Server of the API and admin servers
+type HTTPConfig struct {
+	ReadTimeout               time.Duration `yaml:"read_timeout" env:"HTTPReadTimeout" env-default:"60s" env-description:"max duration for reading the entire request" json:"read_timeout,omitempty"`
+	ReadHeaderTimeout         time.Duration `yaml:"read_header_timeout" env:"HTTPReadHeaderTimeout" env-default:"10s" env-description:"max duration for reading request headers" json:"read_header_timeout,omitempty"`
+	WriteTimeout              time.Duration `yaml:"write_timeout" env:"HTTPWriteTimeout" env-description:"max duration before timing out writes of the response, no limit if zero" json:"write_timeout,omitempty"`
+	IdleTimeout               time.Duration `yaml:"idle_timeout" env:"HTTPIdleTimeout" env-default:"120s" env-description:"max duration to wait for the next request on keep-alive connections" json:"idle_timeout,omitempty"`
+	MaxHeaderBytes            int           `yaml:"max_header_bytes" env:"HTTPMaxHeaderBytes" env-default:"1048576" env-description:"max bytes of request headers" json:"max_header_bytes,omitempty"`
+	HTTP2MaxConcurrentStreams uint32        `yaml:"http2_max_concurrent_streams" env:"HTTP2MaxConcurrentStreams" env-default:"250" env-description:"max concurrent streams per HTTP/2 connection" json:"http2_max_concurrent_streams,omitempty"`
+	HTTP2MaxReadFrameSize     uint32        `yaml:"http2_max_read_frame_size" env:"HTTP2MaxReadFrameSize" env-description:"max HTTP/2 frame size to read, 16KB to 16MB, 1MB if zero" json:"http2_max_read_frame_size,omitempty"`
+	H2C                       bool          `yaml:"h2c" env:"HTTPH2C" env-description:"serve HTTP/2 over cleartext on the API server" json:"h2c,omitempty"`
+}
+
+// Validate returns all problems of the config
+func (c HTTPConfig) Validate() (errs []error) {
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"read_timeout", c.ReadTimeout},
+		{"read_header_timeout", c.ReadHeaderTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"idle_timeout", c.IdleTimeout},
+	} {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", d.name))
+		}
+	}
+
+	if c.MaxHeaderBytes < 0 {
+		errs = append(errs, fmt.Errorf("max_header_bytes must not be negative"))
+	}
+
+	if c.HTTP2MaxReadFrameSize != 0 && (c.HTTP2MaxReadFrameSize < 16<<10 || c.HTTP2MaxReadFrameSize > 1<<24-1) {
+		errs = append(errs, fmt.Errorf("http2_max_read_frame_size %d is out of range 16KB-16MB", c.HTTP2MaxReadFrameSize))
+	}
+
+	return errs
+}
+
+// apply sets the timeouts and the HTTP/2 settings of srv, the handler is wrapped by h2c if withH2C is set.
+// It must be called after srv.TLSConfig is set.
+func (c HTTPConfig) apply(srv *http.Server, withH2C bool) error {
+	srv.ReadTimeout = c.ReadTimeout
+	srv.ReadHeaderTimeout = c.ReadHeaderTimeout
+	srv.WriteTimeout = c.WriteTimeout
+	srv.IdleTimeout = c.IdleTimeout
+	srv.MaxHeaderBytes = c.MaxHeaderBytes
+
+	h2s := &http2.Server{
+		MaxConcurrentStreams: c.HTTP2MaxConcurrentStreams,
+		MaxReadFrameSize:     c.HTTP2MaxReadFrameSize,
+		IdleTimeout:          c.IdleTimeout,
+	}
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return fmt.Errorf("configure http2 failed: %s", err.Error())
+	}
+
+	if withH2C {
+		srv.Handler = h2c.NewHandler(srv.Handler, h2s)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+@Date: 2026/10/19 01:05
+@Author: yvanz
+@File : http_test
+*/
+
+package apiserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/net/http2"
+)
+
+func TestH2C(t *testing.T) {
+	c := testConfig(t)
+	c.App.HTTP = HTTPConfig{
+		ReadHeaderTimeout:         time.Second,
+		HTTP2MaxConcurrentStreams: 10,
+		H2C:                       true,
+	}
+	server := startServer(t, c, func(_ opentracing.Tracer, g *gin.Engine) {
+		g.GET("/proto", func(c *gin.Context) {
+			c.String(http.StatusOK, c.Request.Proto)
+		})
+	})
+
+	api, err := server.apiServer()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if api.srv.ReadHeaderTimeout != time.Second {
+		t.Errorf("want read header timeout 1s, got %v", api.srv.ReadHeaderTimeout)
+	}
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp := waitForClient(t, client, fmt.Sprintf("http://127.0.0.1:%d/proto", c.App.APIPort))
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("want HTTP/2 over cleartext, got %s", resp.Proto)
+	}
+}
+
+func TestValidateHTTP(t *testing.T) {
+	c := HTTPConfig{ReadTimeout: -time.Second, MaxHeaderBytes: -1, HTTP2MaxReadFrameSize: 1024}
+	if errs := c.Validate(); len(errs) != 3 {
+		t.Errorf("want 3 problems, got %v", errs)
+	}
+}
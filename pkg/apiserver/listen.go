@@ -0,0 +1,175 @@
+/*
+@Date: 2026/10/18 23:05
+@Author: yvanz
+@File : listen
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tcpScheme  = "tcp://"
+	unixScheme = "unix://"
+	fdScheme   = "fd://"
+
+	// the first fd passed by systemd socket activation
+	listenFDsStart = 3
+)
+
+// listenAddr returns listen if it is set, otherwise host:port
+func listenAddr(listen, host string, port int) string {
+	if listen != "" {
+		return listen
+	}
+
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+func parseSocketMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return 0o660, nil
+	}
+
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 0o777 {
+		return 0, fmt.Errorf("invalid unix_socket_mode %q, should be octal such as 0660", mode)
+	}
+
+	return os.FileMode(m), nil
+}
+
+func validateListenAddr(addr string) error {
+	switch {
+	case strings.HasPrefix(addr, unixScheme):
+		if strings.TrimPrefix(addr, unixScheme) == "" {
+			return fmt.Errorf("no path in %s", addr)
+		}
+	case strings.HasPrefix(addr, fdScheme):
+		ref := strings.TrimPrefix(addr, fdScheme)
+		if n, err := strconv.Atoi(ref); ref == "" || (err == nil && n < listenFDsStart) {
+			return fmt.Errorf("invalid fd in %s, should be %d or greater, or a name in LISTEN_FDNAMES", addr, listenFDsStart)
+		}
+	default:
+		if _, _, err := net.SplitHostPort(strings.TrimPrefix(addr, tcpScheme)); err != nil {
+			return fmt.Errorf("invalid listen address %s: %s", addr, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// listen supports host:port (or tcp://host:port), unix:///path/to/sock, and fd://3 or fd://<name>
+// for listeners inherited from systemd socket activation or a parent process
+func listen(addr string, socketMode os.FileMode) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, unixScheme):
+		return listenUnix(strings.TrimPrefix(addr, unixScheme), socketMode)
+	case strings.HasPrefix(addr, fdScheme):
+		return listenFD(strings.TrimPrefix(addr, fdScheme))
+	default:
+		return net.Listen("tcp", strings.TrimPrefix(addr, tcpScheme))
+	}
+}
+
+func listenUnix(path string, socketMode os.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.Chmod(path, socketMode); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// removeStaleSocket removes the socket left by a process which did not exit cleanly
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a unix socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+
+	return os.Remove(path)
+}
+
+func listenFD(ref string) (net.Listener, error) {
+	fd, err := strconv.Atoi(ref)
+	if err != nil {
+		if fd, err = systemdFD(ref); err != nil {
+			return nil, err
+		}
+	} else if n, ok := systemdFDs(); ok && fd >= listenFDsStart+n {
+		return nil, fmt.Errorf("fd %d is not passed, LISTEN_FDS is %d", fd, n)
+	}
+
+	f := os.NewFile(uintptr(fd), fdScheme+ref)
+	if f == nil {
+		return nil, fmt.Errorf("invalid fd %d", fd)
+	}
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("fd %d is not a listener: %s", fd, err.Error())
+	}
+
+	return l, nil
+}
+
+// systemdFDs returns LISTEN_FDS if the fds are passed to this process
+func systemdFDs() (int, bool) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// systemdFD finds the fd by its name in LISTEN_FDNAMES, see FileDescriptorName of systemd.socket
+func systemdFD(name string) (int, error) {
+	n, ok := systemdFDs()
+	if !ok {
+		return 0, fmt.Errorf("no fds are passed by systemd to find %s", name)
+	}
+
+	for i, fdName := range strings.Split(os.Getenv("LISTEN_FDNAMES"), ":") {
+		if fdName == name && i < n {
+			return listenFDsStart + i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("fd named %s is not found in LISTEN_FDNAMES", name)
+}
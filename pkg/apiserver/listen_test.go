@@ -0,0 +1,90 @@
+/*
+@Date: 2026/10/18 23:25
+@Author: yvanz
+@File : listen_test
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnixAndFDListeners(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "admin.sock")
+
+	// a socket left by a crashed process
+	stale, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	f, err := inherited.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	apiAddr := inherited.Addr().String()
+	inherited.Close()
+
+	c := testConfig(t)
+	c.App.APIListen = fmt.Sprintf("fd://%d", f.Fd())
+	c.App.AdminListen = "unix://" + sock
+	c.App.UnixSocketMode = "0600"
+	server := startServer(t, c, nil)
+
+	resp := waitForServer(t, "http://"+apiAddr+"/ping")
+	resp.Body.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	if resp, err = client.Get("http://admin/healthz"); err != nil {
+		t.Fatal(err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("want 200 from the unix socket, got %d", resp.StatusCode)
+	}
+
+	if fi, e := os.Stat(sock); e != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("want socket mode 0600, got %v", fi.Mode())
+	}
+
+	if err = server.stop(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err = os.Stat(sock); !os.IsNotExist(err) {
+		t.Error("socket is not removed after shutdown")
+	}
+}
+
+func TestValidateListenAddr(t *testing.T) {
+	for addr, valid := range map[string]bool{
+		"127.0.0.1:80":     true,
+		"tcp://:8080":      true,
+		"unix:///run/a.sk": true,
+		"fd://3":           true,
+		"fd://admin":       true,
+		"localhost":        false,
+		"unix://":          false,
+		"fd://2":           false,
+	} {
+		if err := validateListenAddr(addr); (err == nil) != valid {
+			t.Errorf("%s: want valid %v, got %v", addr, valid, err)
+		}
+	}
+}
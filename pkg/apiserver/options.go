@@ -6,8 +6,31 @@
 
 package apiserver
 
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/yvanz/gin-tmpl/pkg/apiserver/conf"
+	"google.golang.org/grpc"
+)
+
+const (
+	// ServeModeAll starts the API server, the admin server and the workers
+	ServeModeAll = "all"
+	// ServeModeAPI starts the API server and the admin server, workers are ignored
+	ServeModeAPI = "api"
+	// ServeModeWorker starts the admin server and the workers, the API engine is not built at all
+	ServeModeWorker = "worker"
+)
+
 type serverOptions struct {
+	reloader           *conf.Reloader
+	engines            map[string]func(opentracing.Tracer, *gin.Engine)
+	grpcRegister       func(opentracing.Tracer, *grpc.Server)
+	grpcOptions        []grpc.ServerOption
 	migrationList      []interface{}
+	components         []Component
+	serveMode          string
+	reloadSection      string
 	tableColumnWithRaw bool
 }
 
@@ -20,3 +43,43 @@ func Migration(tables []interface{}) ServerOption {
 func RawColumn(raw bool) ServerOption {
 	return func(o *serverOptions) { o.tableColumnWithRaw = raw }
 }
+
+// Components registers extra components, they are started after the built-in clients unless they depend on each other
+func Components(components ...Component) ServerOption {
+	return func(o *serverOptions) { o.components = append(o.components, components...) }
+}
+
+// ServeMode decides which listeners and workers the server starts, ServeModeAll by default
+func ServeMode(mode string) ServerOption {
+	return func(o *serverOptions) { o.serveMode = mode }
+}
+
+// ConfigReloader reloads the config by r on SIGHUP, changes of the config file or POST /config/reload
+// of the admin server, and applies the changes of log, mysql and tracer on the fly.
+// section is the yaml path of APIConfig in the config reloaded by r, e.g. "base".
+func ConfigReloader(r *conf.Reloader, section string) ServerOption {
+	return func(o *serverOptions) {
+		o.reloader = r
+		o.reloadSection = section
+	}
+}
+
+// GRPCServer registers the services of the gRPC server, which is started along with the API server
+// if grpc_port or grpc_listen is set. opts are passed to grpc.NewServer after the built-in interceptors.
+func GRPCServer(register func(opentracing.Tracer, *grpc.Server), opts ...grpc.ServerOption) ServerOption {
+	return func(o *serverOptions) {
+		o.grpcRegister = register
+		o.grpcOptions = opts
+	}
+}
+
+// Engine registers the routes and middleware of the engine configured in app.engines with the same name.
+// The engine comes with metrics, recovery and access log, and the client certificate middleware if client_ca_file is set.
+func Engine(name string, register func(opentracing.Tracer, *gin.Engine)) ServerOption {
+	return func(o *serverOptions) {
+		if o.engines == nil {
+			o.engines = make(map[string]func(opentracing.Tracer, *gin.Engine))
+		}
+		o.engines[name] = register
+	}
+}
@@ -0,0 +1,180 @@
+/*
+@Date: 2026/10/18 20:50
+@Author: yvanz
+@File : reload
+*/
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/gormdb"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"github.com/yvanz/gin-tmpl/pkg/middleware"
+	"github.com/yvanz/gin-tmpl/pkg/ratelimit"
+	"github.com/yvanz/gin-tmpl/pkg/rbac"
+	"github.com/yvanz/gin-tmpl/pkg/tracer"
+)
+
+func (s *Server) subscribeReload(section string) error {
+	prefix := ""
+	if section != "" {
+		prefix = section + "."
+	}
+
+	subscribers := []struct {
+		fn      func(old, new interface{}) error
+		section string
+	}{
+		{s.reloadLog, "log"},
+		{reloadMySQL, "mysql"},
+		{reloadTracer, "tracer"},
+		{reloadCors, "app.cors"},
+		{reloadRequestLog, "app.request_log"},
+		{reloadAccessLog, "app.access_log"},
+		{reloadRBAC, "rbac"},
+		{reloadRateLimit, "rate_limit"},
+		{reloadTimeout, "timeout"},
+	}
+
+	for _, sub := range subscribers {
+		if err := s.reloader.Subscribe(prefix+sub.section, sub.fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) reloadLog(_, new interface{}) error {
+	c := new.(logger.Config)
+	if c.LogName == "" {
+		c.LogName = s.conf.App.ServiceName
+	}
+
+	logger.ConfigureLogger(&logger.Options{Config: c})
+	logger.Infof("log config reloaded, level is %s", c.Level)
+
+	return nil
+}
+
+func reloadMySQL(_, new interface{}) error {
+	c := new.(gormdb.DBConfig)
+	gormdb.ReloadLogger(c)
+	logger.Infof("mysql log config reloaded, log level is %s, slow threshold is %v", c.LogLevel, c.SlowThreshold)
+
+	return nil
+}
+
+func reloadTracer(_, new interface{}) error {
+	c := new.(tracer.Config)
+	if err := tracer.SetSampler(c); err != nil {
+		return err
+	}
+
+	logger.Infof("tracer sampler reloaded, type is %q, param is %v", c.SamplerType, c.SamplerParam)
+	return nil
+}
+
+func reloadCors(_, new interface{}) error {
+	c := new.(middleware.CorsConfig)
+	if err := middleware.SetCors(c); err != nil {
+		return err
+	}
+
+	logger.Infof("cors policy reloaded, allowed origins are %v", c.AllowOrigins)
+	return nil
+}
+
+func reloadRequestLog(_, new interface{}) error {
+	c := new.(middleware.RequestLogConfig)
+	if err := middleware.SetRequestLog(c); err != nil {
+		return err
+	}
+
+	logger.Infof("request log reloaded, redacted keys are %v, max body size is %d", c.RedactKeys, c.MaxBodySize)
+	return nil
+}
+
+func reloadAccessLog(_, new interface{}) error {
+	c := new.(middleware.AccessLogConfig)
+	if err := middleware.SetAccessLog(c); err != nil {
+		return err
+	}
+
+	logger.Infof("access log reloaded, format is %s, sample rates are %v", c.Format, c.SampleRates)
+	return nil
+}
+
+func reloadRBAC(_, new interface{}) error {
+	c := new.(rbac.Config)
+	if e := rbac.Default(); e != nil && e.DryRun() != c.DryRun {
+		e.SetDryRun(c.DryRun)
+		logger.Infof("rbac dry run is set to %v", c.DryRun)
+	}
+
+	return nil
+}
+
+func reloadRateLimit(_, new interface{}) error {
+	c := new.(map[string]ratelimit.Config)
+	if err := ratelimit.Set(c); err != nil {
+		return err
+	}
+
+	logger.Infof("rate limits reloaded, %d limiters", len(c))
+	return nil
+}
+
+func reloadTimeout(_, new interface{}) error {
+	c := new.(map[string]time.Duration)
+	if err := middleware.SetTimeouts(c); err != nil {
+		return err
+	}
+
+	logger.Infof("timeouts reloaded, %d budgets", len(c))
+	return nil
+}
+
+func (s *Server) reload() {
+	if err := s.reloader.Reload(); err != nil {
+		logger.Errorf("reload config failed: %s", err.Error())
+	}
+}
+
+func (s *Server) watchConfig(ctx context.Context) {
+	if err := s.reloader.Watch(ctx); err != nil {
+		logger.Errorf("watch config failed, reload it by SIGHUP or the admin server instead: %s", err.Error())
+	}
+}
+
+func (s *Server) reloadHandler(c *gin.Context) {
+	if err := s.reloader.Reload(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "config reloaded"})
+}
+
+// rbacReloadHandler reloads the policies from the file or the table, the current ones are kept if it fails
+func rbacReloadHandler(c *gin.Context) {
+	e := rbac.Default()
+	if e == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": rbac.ErrNotConfigured.Error()})
+		return
+	}
+
+	if err := e.Load(c.Request.Context()); err != nil {
+		logger.Errorf("reload rbac policies failed: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	logger.Info("rbac policies reloaded")
+	c.JSON(http.StatusOK, gin.H{"message": "rbac policies reloaded"})
+}
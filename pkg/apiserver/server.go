@@ -8,31 +8,47 @@ package apiserver
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/opentracing/opentracing-go"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
+	"github.com/yvanz/gin-tmpl/pkg/apiserver/conf"
 	"github.com/yvanz/gin-tmpl/pkg/ginpprof"
+	"github.com/yvanz/gin-tmpl/pkg/grpcserver"
+	"github.com/yvanz/gin-tmpl/pkg/health"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
 	"github.com/yvanz/gin-tmpl/pkg/middleware"
+	"github.com/yvanz/gin-tmpl/pkg/ratelimit"
 	"github.com/yvanz/gin-tmpl/pkg/tracer"
 )
 
 type Server struct {
+	workers     workerGroup
+	components  componentRegistry
 	traceIO     io.Closer
 	logger      *logger.DemoLog
 	adminEngine *gin.Engine
 	engine      *gin.Engine
+	engines     []namedEngine
+	grpc        *grpcserver.Server
 	tracer      opentracing.Tracer
+	reloader    *conf.Reloader
+	upgrades    chan struct{}
+	serveMode   string
 	conf        APIConfig
 }
 
 // CreateNewServer create a new server with gin
 func CreateNewServer(ctx context.Context, c APIConfig, registerHandler func(opentracing.Tracer, *gin.Engine), opts ...ServerOption) *Server {
-	server, err := newServer(ctx, c, registerHandler, opts)
+	server, err := NewServer(ctx, c, registerHandler, opts...)
 	if err != nil {
 		logger.Fatal(err)
 	}
@@ -40,15 +56,28 @@ func CreateNewServer(ctx context.Context, c APIConfig, registerHandler func(open
 	return server
 }
 
+// NewServer is the same as CreateNewServer but returns the error instead of exiting
+func NewServer(ctx context.Context, c APIConfig, registerHandler func(opentracing.Tracer, *gin.Engine), opts ...ServerOption) (*Server, error) {
+	return newServer(ctx, c, registerHandler, opts)
+}
+
 func newServer(ctx context.Context, c APIConfig, registerHandler func(opentracing.Tracer, *gin.Engine), options []ServerOption) (server *Server, err error) {
-	opts := &serverOptions{}
+	opts := &serverOptions{serveMode: ServeModeAll}
 	for _, o := range options {
 		o(opts)
 	}
 
+	switch opts.serveMode {
+	case ServeModeAll, ServeModeAPI, ServeModeWorker:
+	default:
+		return nil, fmt.Errorf("unsupported serve mode: %s", opts.serveMode)
+	}
+
 	server = &Server{
-		conf:   c,
-		logger: c.buildLogger(),
+		conf:      c,
+		serveMode: opts.serveMode,
+		logger:    c.buildLogger(),
+		upgrades:  make(chan struct{}, 1),
 	}
 
 	// tracer 初始化必须在其他组件之前
@@ -63,10 +92,86 @@ func newServer(ctx context.Context, c APIConfig, registerHandler func(opentracin
 		server.traceIO = cli
 	}
 
-	server.initGin(registerHandler)
+	if opts.reloader != nil {
+		server.reloader = opts.reloader
+		if err = server.subscribeReload(opts.reloadSection); err != nil {
+			return
+		}
+	}
+
+	// the admin server logs the access in every serve mode
+	if err = middleware.SetAccessLog(c.App.AccessLog); err != nil {
+		return
+	}
+
+	if opts.serveMode != ServeModeWorker {
+		if err = middleware.SetCors(c.App.CORS); err != nil {
+			return
+		}
+		if err = middleware.SetRequestLog(c.App.RequestLog); err != nil {
+			return
+		}
+		if err = ratelimit.Set(c.RateLimit); err != nil {
+			return
+		}
+		if err = middleware.SetTimeouts(c.Timeout); err != nil {
+			return
+		}
+
+		server.initGin(registerHandler)
+		if err = server.initEngines(opts.engines); err != nil {
+			return
+		}
+
+		if c.App.grpcEnabled() {
+			server.grpc = grpcserver.New(server.GetTracer(), opts.grpcRegister, opts.grpcOptions...)
+		}
+	}
 	server.initAdmin()
 
-	return server, c.initService(ctx, opts)
+	for _, component := range append(c.builtinComponents(opts), opts.components...) {
+		if err = server.components.add(component); err != nil {
+			return
+		}
+	}
+
+	if err = server.components.start(ctx); err != nil {
+		server.stopComponents()
+		return
+	}
+
+	// components are stopped in reverse order after all servers are shut down
+	AddShutdownListener(func() {
+		server.stopComponents()
+	})
+
+	return server, nil
+}
+
+// RegisterComponent registers and starts a component, the components it depends on must be registered already
+func (s *Server) RegisterComponent(ctx context.Context, c Component) error {
+	if err := s.components.add(c); err != nil {
+		return err
+	}
+
+	if err := s.components.start(ctx); err != nil {
+		s.components.remove(c)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) stopComponents() {
+	timeout := s.conf.App.DrainTimeout
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	s.components.stop(ctx)
 }
 
 func (s *Server) initGin(registerHandler func(opentracing.Tracer, *gin.Engine)) {
@@ -80,7 +185,10 @@ func (s *Server) initGin(registerHandler func(opentracing.Tracer, *gin.Engine))
 	}
 
 	g := gin.New()
-	g.Use(gin.Recovery(), middleware.GinFormatterLog(), middleware.Cors())
+	g.Use(middleware.RequestID(), middleware.Metrics(), gin.Recovery(), middleware.AccessLog(), middleware.Cors())
+	if s.conf.App.ClientCAFile != "" {
+		g.Use(middleware.ClientCert())
+	}
 
 	g.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, map[string]interface{}{
@@ -107,10 +215,21 @@ func (s *Server) initAdmin() {
 	gin.DisableConsoleColor()
 
 	g := gin.New()
-	g.Use(middleware.GinFormatterLog(), gin.Recovery())
+	g.Use(middleware.AccessLog(), gin.Recovery())
 
 	ginpprof.Wrap(g)
 	logger.Wrap(g)
+	health.Wrap(g)
+
+	if s.reloader != nil {
+		g.POST("/config/reload", s.reloadHandler)
+	}
+	if s.conf.App.GracefulUpgrade {
+		g.POST("/upgrade", s.upgradeHandler)
+	}
+	if s.conf.RBAC.Enabled() {
+		g.POST("/rbac/reload", rbacReloadHandler)
+	}
 
 	s.adminEngine = g
 }
@@ -119,45 +238,201 @@ func (s *Server) GetTracer() opentracing.Tracer {
 	return s.tracer
 }
 
+// Start runs the server until it receives SIGTERM or SIGINT, errors are fatal.
+// Use Run to handle the error by yourself.
 func (s *Server) Start() {
-	s.logger.Infof("starting server at %s: %d", s.conf.App.HostIP, s.conf.App.APIPort)
+	if err := s.Run(context.Background()); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// StartAdminOnly runs the admin server only, errors are fatal.
+// Use RunAdminOnly to handle the error by yourself.
+func (s *Server) StartAdminOnly() {
+	if err := s.RunAdminOnly(context.Background()); err != nil {
+		logger.Fatal(err)
+	}
+}
 
-	go func() {
-		s.logger.Infof("starting admin server at %s: %d", s.conf.App.HostIP, s.conf.App.AdminPort)
-		err := StartHTTP(s.conf.App.HostIP, s.conf.App.AdminPort, s.adminEngine)
-		handleError(err)
-	}()
+// Run starts the listeners and workers required by the serve mode, then blocks until ctx is done,
+// a SIGTERM/SIGINT is received or one of the servers fails.
+// Everything is shut down gracefully before it returns.
+func (s *Server) Run(ctx context.Context) error {
+	if s.serveMode == ServeModeWorker {
+		return s.RunAdminOnly(ctx)
+	}
 
-	var err error
-	if s.conf.App.CertFile != "" && s.conf.App.KeyFile != "" {
-		err = StartHTTPS(s.conf, s.engine)
-	} else {
-		err = StartHTTP(s.conf.App.HostIP, s.conf.App.APIPort, s.engine)
+	api, err := s.apiServer()
+	if err != nil {
+		return err
+	}
+	admin, err := s.adminServer()
+	if err != nil {
+		return err
+	}
+
+	public := []*httpServer{api}
+	for _, named := range s.engines {
+		srv, e := s.engineServer(named.conf.Name, named.conf.app(s.conf.App), named.engine)
+		if e != nil {
+			return e
+		}
+		public = append(public, srv)
+	}
+	if s.grpc != nil {
+		grpcSrv, e := s.grpcServer()
+		if e != nil {
+			return e
+		}
+		public = append(public, grpcSrv)
 	}
 
-	handleError(err)
+	return s.run(ctx, public, admin)
 }
 
-func (s *Server) StartAdminOnly() {
-	s.logger.Infof("starting admin server at %s: %d", s.conf.App.HostIP, s.conf.App.AdminPort)
-	err := StartHTTP(s.conf.App.HostIP, s.conf.App.AdminPort, s.adminEngine)
+// ServeMode returns the mode set by the ServeMode option, one of ServeModeAll, ServeModeAPI and ServeModeWorker
+func (s *Server) ServeMode() string {
+	return s.serveMode
+}
+
+// RunAdminOnly is the same as Run but without the API server
+func (s *Server) RunAdminOnly(ctx context.Context) error {
+	admin, err := s.adminServer()
+	if err != nil {
+		return err
+	}
 
-	handleError(err)
+	return s.run(ctx, nil, admin)
 }
 
-func (s *Server) Stop() {
-	_ = s.logger.Sync()
+func (s *Server) apiServer() (*httpServer, error) {
+	return s.engineServer("api", s.conf.App, s.engine)
+}
 
-	if s.tracer != nil {
-		_ = s.traceIO.Close()
+func (s *Server) adminServer() (*httpServer, error) {
+	socketMode, err := parseSocketMode(s.conf.App.UnixSocketMode)
+	if err != nil {
+		return nil, err
 	}
+
+	admin := newHTTPServer("admin", listenAddr(s.conf.App.AdminListen, s.conf.App.HostIP, s.conf.App.AdminPort), s.adminEngine, nil)
+	admin.socketMode = socketMode
+
+	return admin, s.conf.App.HTTP.apply(admin.srv, false)
 }
 
-func handleError(err error) {
-	// ErrServerClosed means the server is closed manually
-	if err == nil || err == http.ErrServerClosed {
-		return
+func (s *Server) grpcServer() (*httpServer, error) {
+	socketMode, err := parseSocketMode(s.conf.App.UnixSocketMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpServer{
+		name: "grpc",
+		addr: listenAddr(s.conf.App.GRPCListen, s.conf.App.HostIP, s.conf.App.GRPCPort),
+		run: func(l net.Listener) error {
+			s.grpc.Serving()
+			return s.grpc.Serve(l)
+		},
+		shutdown:   s.grpc.Shutdown,
+		socketMode: socketMode,
+	}, nil
+}
+
+func (s *Server) run(ctx context.Context, public []*httpServer, admin *httpServer) error {
+	var extra []os.Signal
+	if s.reloader != nil {
+		extra = append(extra, syscall.SIGHUP)
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go s.watchConfig(watchCtx)
+	}
+
+	if s.conf.App.GracefulUpgrade {
+		extra = append(extra, syscall.SIGUSR2)
+	}
+
+	servers := append(append([]*httpServer{}, public...), admin)
+	for i, srv := range servers {
+		if err := srv.listen(); err != nil {
+			for _, opened := range servers[:i] {
+				_ = opened.listener.Close()
+			}
+
+			return fmt.Errorf("%s server: %s", srv.name, err.Error())
+		}
 	}
 
-	logger.Fatal(err)
+	signals := notifySignals(extra...)
+	defer signal.Stop(signals)
+
+	// workers are canceled by gracefulStop rather than ctx, so that they stop after the public servers
+	s.workers.start(context.Background())
+
+	errChan := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func(srv *httpServer) {
+			s.logger.Infof("starting %s server at %s", srv.name, srv.listener.Addr())
+			errChan <- srv.serve()
+		}(srv)
+	}
+	notifyUpgradeReady()
+
+	for {
+		select {
+		case sig := <-signals:
+			switch sig {
+			case syscall.SIGUSR1:
+				dumpGoroutines()
+				continue
+			case syscall.SIGHUP:
+				s.logger.Info("got signal SIGHUP, reloading config...")
+				s.reload()
+				continue
+			case syscall.SIGUSR2:
+				s.triggerUpgrade()
+				continue
+			}
+
+			s.logger.Infof("got signal %s, shutting down...", sig)
+			return s.gracefulStop(public, admin)
+		case <-s.upgrades:
+			if !s.conf.App.GracefulUpgrade {
+				continue
+			}
+
+			s.logger.Info("upgrading, starting the new process...")
+			if err := s.upgrade(servers); err != nil {
+				s.logger.Errorf("upgrade failed, keep serving: %s", err.Error())
+				continue
+			}
+
+			s.logger.Info("the new process took over, shutting down...")
+			return s.gracefulStop(public, admin)
+		case <-ctx.Done():
+			s.logger.Info("context done, shutting down...")
+			return s.gracefulStop(public, admin)
+		case err := <-errChan:
+			if err == nil {
+				continue
+			}
+
+			s.logger.Errorf("server exited unexpectedly: %s, shutting down...", err.Error())
+			if e := s.gracefulStop(public, admin); e != nil {
+				logger.Error(e)
+			}
+
+			return err
+		}
+	}
+}
+
+func (s *Server) Stop() {
+	s.stopComponents()
+	_ = s.logger.Sync()
+
+	if s.tracer != nil {
+		_ = s.traceIO.Close()
+	}
 }
@@ -0,0 +1,157 @@
+/*
+@Date: 2026/10/18 14:20
+@Author: yvanz
+@File : server_test
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+)
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func waitForServer(t *testing.T, url string) *http.Response {
+	return waitForClient(t, http.DefaultClient, url)
+}
+
+// waitForClient gets url by client until the server is ready
+func waitForClient(t *testing.T, client *http.Client, url string) *http.Response {
+	var lastErr error
+	for i := 0; i < 50; i++ {
+		resp, err := client.Get(url)
+		if err == nil {
+			return resp
+		}
+
+		lastErr = err
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("server is not ready: %v", lastErr)
+	return nil
+}
+
+// testConfig returns the config of a server listening on free loopback ports
+func testConfig(t *testing.T) APIConfig {
+	return APIConfig{App: AppConfig{
+		ServiceName:      "test",
+		HostIP:           "127.0.0.1",
+		RunMode:          RunModeTest,
+		APIPort:          freePort(t),
+		AdminPort:        freePort(t),
+		DrainTimeout:     time.Second,
+		ForceQuitTimeout: 2 * time.Second,
+	}}
+}
+
+type testServer struct {
+	*Server
+	cancel   context.CancelFunc
+	errChan  chan error
+	stopOnce sync.Once
+	err      error
+}
+
+// startServer validates c, builds the server and runs it, it is stopped and waited for when the test ends
+func startServer(t *testing.T, c APIConfig, registerHandler func(opentracing.Tracer, *gin.Engine), opts ...ServerOption) *testServer {
+	if errs := c.App.Validate(); len(errs) > 0 {
+		t.Fatalf("config should be valid: %v", errs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server, err := NewServer(ctx, c, registerHandler, opts...)
+	if err != nil {
+		cancel()
+		t.Fatal(err.Error())
+	}
+
+	s := &testServer{Server: server, cancel: cancel, errChan: make(chan error, 1)}
+	go func() {
+		s.errChan <- server.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		_ = s.stop()
+	})
+
+	return s
+}
+
+// stop cancels the server and returns the result of Run
+func (s *testServer) stop() error {
+	s.stopOnce.Do(func() {
+		s.cancel()
+		select {
+		case s.err = <-s.errChan:
+		case <-time.After(3 * time.Second):
+			s.err = fmt.Errorf("server is still running after ctx is canceled")
+		}
+	})
+
+	return s.err
+}
+
+func TestServerRun(t *testing.T) {
+	c := testConfig(t)
+	server := startServer(t, c, nil)
+
+	for _, url := range []string{
+		fmt.Sprintf("http://127.0.0.1:%d/ping", c.App.APIPort),
+		fmt.Sprintf("http://127.0.0.1:%d/healthz", c.App.AdminPort),
+	} {
+		resp := waitForServer(t, url)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: want 200, got %d", url, resp.StatusCode)
+		}
+	}
+
+	if err := server.stop(); err != nil {
+		t.Errorf("run returns an error: %s", err.Error())
+	}
+}
+
+func TestServeMode(t *testing.T) {
+	c := APIConfig{App: AppConfig{ServiceName: "test", RunMode: RunModeTest}}
+
+	server, err := NewServer(context.Background(), c, nil, ServeMode(ServeModeWorker))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if server.engine != nil || server.adminEngine == nil {
+		t.Error("worker mode should build the admin engine only")
+	}
+
+	server, err = NewServer(context.Background(), c, nil, ServeMode(ServeModeAPI))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = server.AddWorker("ignored", func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(server.workers.workers) != 0 {
+		t.Error("api mode should ignore workers")
+	}
+
+	if _, err = NewServer(context.Background(), c, nil, ServeMode("cron")); err == nil {
+		t.Error("unsupported serve mode should be rejected")
+	}
+}
@@ -7,24 +7,25 @@
 package apiserver
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
-	"os/signal"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
 const (
-	wrapUpTime = time.Second
-	waitTime   = 5500 * time.Millisecond
+	defaultDrainTimeout     = 5 * time.Second
+	defaultForceQuitTimeout = 10 * time.Second
 )
 
 var (
-	wrapUpListeners          = new(listenerManager)
-	shutdownListeners        = new(listenerManager)
-	delayTimeBeforeForceQuit = waitTime
+	wrapUpListeners   = new(listenerManager)
+	shutdownListeners = new(listenerManager)
 )
 
 // AddShutdownListener adds fn as a shutdown listener.
@@ -39,18 +40,106 @@ func AddWrapUpListener(fn func()) (waitForCalled func()) {
 	return wrapUpListeners.addListener(fn)
 }
 
-func gracefulStop(signals chan os.Signal) {
-	signal.Stop(signals)
+// httpServer serves HTTP, or gRPC when srv is nil, on a listener
+type httpServer struct {
+	srv        *http.Server
+	listener   net.Listener
+	run        func(net.Listener) error
+	shutdown   func(context.Context) error
+	name       string
+	addr       string
+	socketMode os.FileMode
+}
 
-	logger.Info("got signal SIGTERM, shutting down...")
-	wrapUpListeners.notifyListeners()
+// newHTTPServer returns a server listening on listenAddr, see listen for the supported formats.
+// run serves on the listener, http.Server.Serve is used if it is nil.
+func newHTTPServer(name, listenAddr string, handler http.Handler, run func(*http.Server, net.Listener) error) *httpServer {
+	if run == nil {
+		run = func(srv *http.Server, l net.Listener) error {
+			return srv.Serve(l)
+		}
+	}
 
-	time.Sleep(wrapUpTime)
-	shutdownListeners.notifyListeners()
+	srv := &http.Server{Addr: listenAddr, Handler: handler}
+	return &httpServer{
+		name: name,
+		addr: listenAddr,
+		srv:  srv,
+		run: func(l net.Listener) error {
+			return run(srv, l)
+		},
+		shutdown: srv.Shutdown,
+	}
+}
 
-	time.Sleep(delayTimeBeforeForceQuit - wrapUpTime)
-	logger.Infof("still alive after %v, going to force kill the process...", delayTimeBeforeForceQuit)
-	_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+// listen takes the listener inherited from the parent process on graceful upgrade, or opens a new one
+func (h *httpServer) listen() (err error) {
+	if h.listener, err = inheritedListener(h.name); err != nil || h.listener != nil {
+		return
+	}
+
+	h.listener, err = listen(h.addr, h.socketMode)
+	return
+}
+
+func (h *httpServer) serve() error {
+	err := h.run(h.listener)
+	if err == nil || err == http.ErrServerClosed {
+		return nil
+	}
+
+	return fmt.Errorf("%s server: %s", h.name, err.Error())
+}
+
+// gracefulStop shuts down the public servers one by one, then the workers and finally the admin server,
+// so that probes and metrics are still available while draining. Each step gets DrainTimeout.
+// Wrap up listeners are notified before all and shutdown listeners after all.
+// It gives up and returns an error if the whole procedure takes longer than ForceQuitTimeout.
+func (s *Server) gracefulStop(public []*httpServer, admin *httpServer) error {
+	drainTimeout := s.conf.App.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+	forceQuitTimeout := s.conf.App.ForceQuitTimeout
+	if forceQuitTimeout <= 0 {
+		forceQuitTimeout = defaultForceQuitTimeout
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		wrapUpListeners.notifyListeners()
+
+		var err error
+		step := func(name string, stop func(ctx context.Context) error) {
+			s.logger.Infof("shutting down %s", name)
+
+			ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+			defer cancel()
+
+			if e := stop(ctx); e != nil {
+				logger.Errorf("shutdown %s failed: %s", name, e.Error())
+				if err == nil {
+					err = fmt.Errorf("shutdown %s: %s", name, e.Error())
+				}
+			}
+		}
+
+		for _, srv := range public {
+			step(srv.name+" server", srv.shutdown)
+		}
+		step("workers", s.workers.stop)
+		step(admin.name+" server", admin.shutdown)
+
+		shutdownListeners.notifyListeners()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(forceQuitTimeout):
+		return fmt.Errorf("still alive after %v, force quit", forceQuitTimeout)
+	}
 }
 
 type listenerManager struct {
@@ -81,4 +170,7 @@ func (lm *listenerManager) notifyListeners() {
 	for _, listener := range lm.listeners {
 		listener()
 	}
+
+	// every listener is called only once
+	lm.listeners = nil
 }
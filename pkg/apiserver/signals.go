@@ -10,37 +10,16 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-
-	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
 const timeFormat = "0102150405"
 
-var done = make(chan struct{})
-
-func init() {
-	go func() {
-		// https://golang.org/pkg/os/signal/#Notify
-		signals := make(chan os.Signal, 1)
-		signal.Notify(signals, syscall.SIGUSR1, os.Interrupt, syscall.SIGTERM)
-
-		for {
-			v := <-signals
-			switch v {
-			case syscall.SIGUSR1:
-				dumpGoroutines()
-			case syscall.SIGTERM, os.Interrupt:
-				select {
-				case <-done:
-					// already closed
-				default:
-					close(done)
-				}
+// notifySignals relays SIGUSR1 (dump goroutines), SIGTERM/SIGINT (graceful stop) and extra signals to the returned chan,
+// call signal.Stop on it once the server stops.
+func notifySignals(extra ...os.Signal) chan os.Signal {
+	// https://golang.org/pkg/os/signal/#Notify
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append([]os.Signal{syscall.SIGUSR1, os.Interrupt, syscall.SIGTERM}, extra...)...)
 
-				gracefulStop(signals)
-			default:
-				logger.Errorf("got unregistered signal: %+v", v)
-			}
-		}
-	}()
+	return signals
 }
@@ -0,0 +1,215 @@
+/*
+@Date: 2026/10/18 22:10
+@Author: yvanz
+@File : tls
+*/
+
+package apiserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+const (
+	// ClientAuthNone does not request client certificates
+	ClientAuthNone = "none"
+	// ClientAuthRequest requests a client certificate and verifies it if the client sends one
+	ClientAuthRequest = "request"
+	// ClientAuthRequireAndVerify rejects clients without a valid certificate
+	ClientAuthRequireAndVerify = "require-and-verify"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unsupported client_auth %q, only support %s/%s/%s",
+			mode, ClientAuthNone, ClientAuthRequest, ClientAuthRequireAndVerify)
+	}
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported tls_min_version %q, only support 1.0/1.1/1.2/1.3", version)
+	}
+
+	return v, nil
+}
+
+// parseCipherSuites only accepts the secure suites of crypto/tls, suites of TLS 1.3 are not configurable
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	supported := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		supported[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := supported[name]
+		if !ok {
+			all := make([]string, 0, len(supported))
+			for n := range supported {
+				all = append(all, n)
+			}
+			sort.Strings(all)
+
+			return nil, fmt.Errorf("unsupported cipher suite %q, only support %s", name, strings.Join(all, "/"))
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// newTLSConfig builds the TLS config of the API server, the key pair is reloaded by the returned certReloader
+func newTLSConfig(c AppConfig) (*tls.Config, *certReloader, error) {
+	certs := &certReloader{certFile: c.CertFile, keyFile: c.KeyFile}
+	if err := certs.load(); err != nil {
+		return nil, nil, err
+	}
+
+	clientAuth, err := parseClientAuth(c.ClientAuth)
+	if err != nil {
+		return nil, nil, err
+	}
+	minVersion, err := parseTLSVersion(c.TLSMinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipherSuites, err := parseCipherSuites(c.CipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conf := &tls.Config{
+		GetCertificate: certs.getCertificate,
+		ClientAuth:     clientAuth,
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+	}
+
+	if c.ClientCAFile != "" {
+		pem, e := os.ReadFile(c.ClientCAFile)
+		if e != nil {
+			return nil, nil, e
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificate found in client_ca_file %s", c.ClientCAFile)
+		}
+		conf.ClientCAs = pool
+	}
+
+	return conf, certs, nil
+}
+
+// certReloader serves the key pair loaded from files, and loads it again once the files change
+type certReloader struct {
+	cert     *tls.Certificate
+	certFile string
+	keyFile  string
+	lock     sync.RWMutex
+}
+
+func (r *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair failed: %s", err.Error())
+	}
+
+	r.lock.Lock()
+	r.cert = &cert
+	r.lock.Unlock()
+
+	return nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.cert, nil
+}
+
+// watch reloads the key pair on changes of the directories of the files until the returned func is called.
+// The old key pair is kept if the new one fails to load, e.g. only one of the files is replaced yet.
+func (r *certReloader) watch() (stop func()) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Errorf("watch key pair failed, it will not be reloaded: %s", err.Error())
+		return func() {}
+	}
+
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		if err = watcher.Add(dir); err != nil {
+			logger.Errorf("watch %s failed, the key pair will not be reloaded on changes in it: %s", dir, err.Error())
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if event.Op&fsnotify.Chmod == event.Op {
+					continue
+				}
+
+				if e := r.load(); e != nil {
+					logger.Warnf("reload key pair after %s failed, keep the old one: %s", event, e.Error())
+					continue
+				}
+				logger.Infof("key pair %s reloaded", r.certFile)
+			case e, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logger.Errorf("watch key pair failed: %s", e.Error())
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		_ = watcher.Close()
+	}
+}
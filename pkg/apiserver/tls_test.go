@@ -0,0 +1,155 @@
+/*
+@Date: 2026/10/18 22:45
+@Author: yvanz
+@File : tls_test
+*/
+
+package apiserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/yvanz/gin-tmpl/pkg/middleware"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	parentCert, parentKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	keyDER, _ := x509.MarshalECPrivateKey(c.key)
+	for file, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: c.der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	} {
+		if file == "" {
+			continue
+		}
+		if err := os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+}
+
+func (c *testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	ca.write(t, caFile, "")
+
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	newTestCert(t, "server-1", ca).write(t, certFile, keyFile)
+
+	c := testConfig(t)
+	c.App.CertFile, c.App.KeyFile = certFile, keyFile
+	c.App.ClientCAFile, c.App.ClientAuth = caFile, ClientAuthRequireAndVerify
+	startServer(t, c, func(_ opentracing.Tracer, g *gin.Engine) {
+		g.GET("/whoami", func(c *gin.Context) {
+			identity, _ := middleware.GetClientIdentity(c)
+			c.String(http.StatusOK, identity.Name())
+		})
+	})
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	url := fmt.Sprintf("https://127.0.0.1:%d/whoami", c.App.APIPort)
+
+	var served *x509.Certificate
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{newTestCert(t, "client-1", ca).tlsCert()},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			served = cs.PeerCertificates[0]
+			return nil
+		},
+	}, DisableKeepAlives: true}}
+
+	resp := waitForClient(t, client, url)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "client-1" {
+		t.Errorf("want identity client-1, got %q", body)
+	}
+
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	var err error
+	if resp, err = anonymous.Get(url); err == nil {
+		resp.Body.Close()
+		t.Error("clients without certificates should be rejected")
+	}
+
+	newTestCert(t, "server-2", ca).write(t, certFile, keyFile)
+	for i := 0; i < 50 && served.Subject.CommonName != "server-2"; i++ {
+		time.Sleep(20 * time.Millisecond)
+		if resp, err = client.Get(url); err == nil {
+			resp.Body.Close()
+		}
+	}
+	if served.Subject.CommonName != "server-2" {
+		t.Errorf("key pair is not reloaded, still serving %s", served.Subject.CommonName)
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	c := AppConfig{ClientAuth: ClientAuthRequest, TLSMinVersion: "1.4", CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}
+	if errs := c.validateTLS(); len(errs) != 4 {
+		t.Errorf("want 4 problems, got %v", errs)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+@Date: 2026/10/18 23:50
+@Author: yvanz
+@File : upgrade
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// upgradeFDsEnv passes the inherited fds to the new process, e.g. api:3,admin:4,ready:5
+	upgradeFDsEnv   = "APISERVER_UPGRADE_FDS"
+	upgradeReadyFD  = "ready"
+	upgradeFDsStart = 3
+
+	defaultUpgradeTimeout = 30 * time.Second
+)
+
+var (
+	inheritedOnce sync.Once
+	inheritedFDs  map[string]int
+
+	// upgradeCommand returns the new process to start, it is the same as the running one by default
+	upgradeCommand = func() (string, []string, error) {
+		exe, err := os.Executable()
+		return exe, os.Args[1:], err
+	}
+)
+
+// inheritedFD returns the fd passed by the parent process on graceful upgrade.
+// The env is removed once it is read, so that it never leaks to other child processes.
+func inheritedFD(name string) (int, bool) {
+	inheritedOnce.Do(func() {
+		inheritedFDs = make(map[string]int)
+		for _, pair := range strings.Split(os.Getenv(upgradeFDsEnv), ",") {
+			kv := strings.SplitN(pair, ":", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			if fd, err := strconv.Atoi(kv[1]); err == nil {
+				inheritedFDs[kv[0]] = fd
+			}
+		}
+		_ = os.Unsetenv(upgradeFDsEnv)
+	})
+
+	fd, ok := inheritedFDs[name]
+	return fd, ok
+}
+
+// inheritedListener returns nil if the server has no listener inherited
+func inheritedListener(name string) (net.Listener, error) {
+	fd, ok := inheritedFD(name)
+	if !ok {
+		return nil, nil
+	}
+
+	return listenFD(strconv.Itoa(fd))
+}
+
+// notifyUpgradeReady tells the parent process that the servers are listening, so that it could stop
+func notifyUpgradeReady() {
+	fd, ok := inheritedFD(upgradeReadyFD)
+	if !ok {
+		return
+	}
+
+	f := os.NewFile(uintptr(fd), upgradeReadyFD)
+	if f == nil {
+		return
+	}
+	defer f.Close()
+
+	_, _ = f.Write([]byte{1})
+}
+
+// upgrade starts the executable, which is usually replaced with a new binary, with the same arguments
+// and the listeners of servers, then waits until it notifies ready. The caller should stop once it returns nil.
+func (s *Server) upgrade(servers []*httpServer) error {
+	exe, args, err := upgradeCommand()
+	if err != nil {
+		return err
+	}
+
+	files := make([]*os.File, 0, len(servers)+1)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	fds := make([]string, 0, len(servers)+1)
+	for _, srv := range servers {
+		l, ok := srv.listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("listener of %s server could not be passed", srv.name)
+		}
+
+		f, e := l.File()
+		if e != nil {
+			return fmt.Errorf("listener of %s server could not be passed: %s", srv.name, e.Error())
+		}
+
+		files = append(files, f)
+		fds = append(fds, fmt.Sprintf("%s:%d", srv.name, upgradeFDsStart+len(files)-1))
+	}
+
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+	files = append(files, readyW)
+	fds = append(fds, fmt.Sprintf("%s:%d", upgradeReadyFD, upgradeFDsStart+len(files)-1))
+
+	env := make([]string, 0, len(os.Environ())+1)
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, upgradeFDsEnv+"=") {
+			env = append(env, e)
+		}
+	}
+
+	cmd := exec.Command(exe, args...) //nolint:gosec
+	cmd.Env = append(env, upgradeFDsEnv+"="+strings.Join(fds, ","))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	// only the new process holds the write end from now on, reading gets EOF if it exits before ready
+	_ = readyW.Close()
+	readyChan := make(chan error, 1)
+	go func() {
+		_, e := ready.Read(make([]byte, 1))
+		readyChan <- e
+	}()
+
+	timeout := s.conf.App.UpgradeTimeout
+	if timeout <= 0 {
+		timeout = defaultUpgradeTimeout
+	}
+
+	select {
+	case err = <-readyChan:
+		if err != nil {
+			return fmt.Errorf("new process %d exited before ready", cmd.Process.Pid)
+		}
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("new process %d is not ready in %v, killed", cmd.Process.Pid, timeout)
+	}
+
+	// the unix sockets are served by the new process now, keep them on disk
+	for _, srv := range servers {
+		if l, ok := srv.listener.(*net.UnixListener); ok {
+			l.SetUnlinkOnClose(false)
+		}
+	}
+
+	s.logger.Infof("new process %d is ready", cmd.Process.Pid)
+	return nil
+}
+
+// triggerUpgrade asks the running server to upgrade, it is a no-op if an upgrade is pending already
+func (s *Server) triggerUpgrade() {
+	select {
+	case s.upgrades <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) upgradeHandler(c *gin.Context) {
+	s.triggerUpgrade()
+	c.JSON(http.StatusAccepted, gin.H{"message": "upgrading, see the log for the result"})
+}
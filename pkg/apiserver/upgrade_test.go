@@ -0,0 +1,96 @@
+/*
+@Date: 2026/10/19 00:20
+@Author: yvanz
+@File : upgrade_test
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+)
+
+func TestGracefulUpgrade(t *testing.T) {
+	_, isChild := os.LookupEnv(upgradeFDsEnv)
+
+	c := testConfig(t)
+	c.App.UpgradeTimeout = 10 * time.Second
+	c.App.GracefulUpgrade = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, err := NewServer(ctx, c, func(_ opentracing.Tracer, g *gin.Engine) {
+		g.GET("/pid", func(c *gin.Context) {
+			c.String(http.StatusOK, strconv.Itoa(os.Getpid()))
+		})
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the new process serves on the inherited listeners for a while and then exits
+	if isChild {
+		ctx, cancel = context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		if err = server.Run(ctx); err != nil {
+			t.Fatal(err.Error())
+		}
+		return
+	}
+
+	upgradeCommand = func() (string, []string, error) {
+		exe, e := os.Executable()
+		return exe, []string{"-test.run=^TestGracefulUpgrade$"}, e
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Run(ctx)
+	}()
+
+	pidURL := fmt.Sprintf("http://127.0.0.1:%d/pid", c.App.APIPort)
+	resp := waitForServer(t, pidURL)
+	resp.Body.Close()
+
+	resp, err = http.Post(fmt.Sprintf("http://127.0.0.1:%d/upgrade", c.App.AdminPort), "", nil) //nolint:gosec
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("want 202, got %d", resp.StatusCode)
+	}
+
+	select {
+	case err = <-errChan:
+		if err != nil {
+			t.Fatalf("run returns an error after upgrade: %s", err.Error())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("server is still running after upgrade")
+	}
+
+	// the old process stopped, but the port is still served by the new one
+	resp, err = http.Get(pidURL) //nolint:gosec
+	if err != nil {
+		t.Fatalf("port is not served after upgrade: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) == strconv.Itoa(os.Getpid()) {
+		t.Error("port should be served by the new process")
+	}
+}
@@ -0,0 +1,196 @@
+/*
+@Date: 2026/10/18 18:40
+@Author: yvanz
+@File : validate
+*/
+
+package apiserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ValidationErrors collects all problems found in a config
+type ValidationErrors []error
+
+func (v ValidationErrors) Error() string {
+	msg := make([]string, 0, len(v))
+	for _, err := range v {
+		msg = append(msg, err.Error())
+	}
+
+	return fmt.Sprintf("%d problem(s) found in config:\n  - %s", len(v), strings.Join(msg, "\n  - "))
+}
+
+func (v *ValidationErrors) add(section string, errs ...error) {
+	for _, err := range errs {
+		*v = append(*v, fmt.Errorf("%s: %s", section, err.Error()))
+	}
+}
+
+// Validate checks the config and returns ValidationErrors with every problem listed, or nil
+func (c *APIConfig) Validate() error {
+	var errs ValidationErrors
+
+	errs.add("app", c.App.Validate()...)
+	errs.add("log", c.Log.Validate()...)
+	errs.add("tracer", c.Tracer.Validate()...)
+
+	if c.MySQL.WriteDBHost != "" {
+		errs.add("mysql", c.MySQL.Validate()...)
+	}
+	if c.Redis.Addr != "" {
+		errs.add("redis", c.Redis.Validate()...)
+	}
+	if c.Kafka.Addr != "" {
+		errs.add("kafka", c.Kafka.Validate()...)
+	}
+	if c.Auth.Enabled() {
+		errs.add("auth", c.Auth.Validate()...)
+	}
+	for name, limit := range c.RateLimit {
+		errs.add("rate_limit."+name, limit.Validate()...)
+	}
+	for name, d := range c.Timeout {
+		if d < 0 {
+			errs.add("timeout."+name, fmt.Errorf("must not be negative"))
+		}
+	}
+	if c.RBAC.Enabled() {
+		errs.add("rbac", c.RBAC.Validate()...)
+		// the roles are granted to the operators verified by auth or the client certificates only
+		if clientAuth, _ := parseClientAuth(c.App.ClientAuth); !c.Auth.Enabled() && clientAuth == tls.NoClientCert {
+			errs.add("rbac", fmt.Errorf("auth or client_auth is required to verify the operators"))
+		}
+		if c.RBAC.Table != "" && c.MySQL.WriteDBHost == "" {
+			errs.add("rbac", fmt.Errorf("mysql is required by table"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Validate returns all problems of the config
+func (c AppConfig) Validate() (errs []error) {
+	if c.ServiceName == "" {
+		errs = append(errs, fmt.Errorf("service_name is required"))
+	}
+
+	switch c.RunMode {
+	case "", RunModeDebug, RunModeTest, RunModeDev, RunModeProd, RunModeProduction, RunModeRelease:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported run_mode %q, only support %s", c.RunMode, strings.Join([]string{
+			RunModeDebug, RunModeTest, RunModeDev, RunModeProd, RunModeProduction, RunModeRelease}, "/")))
+	}
+
+	for _, p := range []struct {
+		name string
+		port int
+	}{{"api_port", c.APIPort}, {"admin_port", c.AdminPort}} {
+		if p.port < 1 || p.port > 65535 {
+			errs = append(errs, fmt.Errorf("%s %d is out of range 1-65535", p.name, p.port))
+		}
+	}
+	if c.APIPort == c.AdminPort {
+		errs = append(errs, fmt.Errorf("api_port and admin_port must be different"))
+	}
+	if c.GRPCPort != 0 {
+		if c.GRPCPort < 1 || c.GRPCPort > 65535 {
+			errs = append(errs, fmt.Errorf("grpc_port %d is out of range 1-65535", c.GRPCPort))
+		}
+		if c.GRPCPort == c.APIPort || c.GRPCPort == c.AdminPort {
+			errs = append(errs, fmt.Errorf("grpc_port must be different from api_port and admin_port"))
+		}
+	}
+
+	for _, l := range []struct {
+		name string
+		addr string
+	}{{"api_listen", c.APIListen}, {"admin_listen", c.AdminListen}, {"grpc_listen", c.GRPCListen}} {
+		if l.addr == "" {
+			continue
+		}
+
+		if err := validateListenAddr(l.addr); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", l.name, err.Error()))
+		}
+	}
+	if c.APIListen != "" && c.APIListen == c.AdminListen {
+		errs = append(errs, fmt.Errorf("api_listen and admin_listen must be different"))
+	}
+	if c.GRPCListen != "" && (c.GRPCListen == c.APIListen || c.GRPCListen == c.AdminListen) {
+		errs = append(errs, fmt.Errorf("grpc_listen must be different from api_listen and admin_listen"))
+	}
+	if _, err := parseSocketMode(c.UnixSocketMode); err != nil {
+		errs = append(errs, err)
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("cert_file and key_file must be set together"))
+	}
+	errs = append(errs, c.validateTLS()...)
+	if c.HTTP.H2C && c.CertFile != "" {
+		errs = append(errs, fmt.Errorf("http.h2c is for cleartext only, HTTP/2 is negotiated over TLS already"))
+	}
+	for _, f := range []string{c.CertFile, c.KeyFile, c.ClientCAFile} {
+		if f == "" {
+			continue
+		}
+
+		if _, err := os.Stat(f); err != nil {
+			errs = append(errs, fmt.Errorf("tls file: %s", err.Error()))
+		}
+	}
+
+	errs = append(errs, c.validateEngines()...)
+	for _, err := range c.HTTP.Validate() {
+		errs = append(errs, fmt.Errorf("http.%s", err.Error()))
+	}
+	for _, err := range c.CORS.Validate() {
+		errs = append(errs, fmt.Errorf("cors.%s", err.Error()))
+	}
+	for _, err := range c.RequestLog.Validate() {
+		errs = append(errs, fmt.Errorf("request_log.%s", err.Error()))
+	}
+	for _, err := range c.AccessLog.Validate() {
+		errs = append(errs, fmt.Errorf("access_log.%s", err.Error()))
+	}
+
+	if c.DrainTimeout < 0 || c.ForceQuitTimeout < 0 || c.UpgradeTimeout < 0 {
+		errs = append(errs, fmt.Errorf("drain_timeout, force_quit_timeout and upgrade_timeout must not be negative"))
+	}
+	if c.ForceQuitTimeout > 0 && c.ForceQuitTimeout < c.DrainTimeout {
+		errs = append(errs, fmt.Errorf("force_quit_timeout %v is shorter than drain_timeout %v", c.ForceQuitTimeout, c.DrainTimeout))
+	}
+
+	return errs
+}
+
+func (c AppConfig) validateTLS() (errs []error) {
+	clientAuth, err := parseClientAuth(c.ClientAuth)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if clientAuth != tls.NoClientCert && c.ClientCAFile == "" {
+		errs = append(errs, fmt.Errorf("client_ca_file is required when client_auth is %s", c.ClientAuth))
+	}
+	if (clientAuth != tls.NoClientCert || c.ClientCAFile != "") && c.CertFile == "" {
+		errs = append(errs, fmt.Errorf("client certificates are verified over TLS only, set cert_file and key_file"))
+	}
+
+	if _, err = parseTLSVersion(c.TLSMinVersion); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err = parseCipherSuites(c.CipherSuites); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
@@ -0,0 +1,44 @@
+/*
+@Date: 2026/10/19 22:20
+@Author: yvanz
+@File : validate_test
+*/
+
+package apiserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yvanz/gin-tmpl/pkg/jwtauth"
+	"github.com/yvanz/gin-tmpl/pkg/rbac"
+)
+
+func TestValidateRBAC(t *testing.T) {
+	rbacErrors := func(c APIConfig) (n int) {
+		if err, ok := c.Validate().(ValidationErrors); ok {
+			for _, e := range err {
+				if strings.HasPrefix(e.Error(), "rbac:") {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	c := APIConfig{RBAC: rbac.Config{File: "rbac.yaml"}}
+	if n := rbacErrors(c); n != 1 {
+		t.Errorf("rbac without authentication: want 1 problem, got %d", n)
+	}
+
+	c.Auth = jwtauth.Config{HMACSecret: "secret"}
+	if n := rbacErrors(c); n != 0 {
+		t.Errorf("rbac with auth: want no problem, got %d", n)
+	}
+
+	c.Auth = jwtauth.Config{}
+	c.App.ClientAuth = ClientAuthRequireAndVerify
+	if n := rbacErrors(c); n != 0 {
+		t.Errorf("rbac with client certificates: want no problem, got %d", n)
+	}
+}
@@ -0,0 +1,261 @@
+/*
+@Date: 2026/10/18 16:45
+@Author: yvanz
+@File : worker
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/yvanz/gin-tmpl/pkg/health"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+// WorkerFunc runs a background job until ctx is done, such as a Kafka consumer or a loop
+type WorkerFunc func(ctx context.Context) error
+
+type RestartPolicy string
+
+const (
+	// RestartOnFailure restarts the worker when it returns an error or panics
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartAlways restarts the worker whenever it returns before the server stops
+	RestartAlways RestartPolicy = "always"
+	// RestartNever runs the worker only once
+	RestartNever RestartPolicy = "never"
+)
+
+const (
+	workerPending  = "pending"
+	workerRunning  = "running"
+	workerBackoff  = "backoff"
+	workerStopped  = "stopped"
+	workerFailed   = "failed"
+	workerCheckPfx = "worker."
+)
+
+type workerOptions struct {
+	policy      RestartPolicy
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxRestarts int
+}
+
+type WorkerOption func(*workerOptions)
+
+// WorkerRestart sets the restart policy, RestartOnFailure by default
+func WorkerRestart(policy RestartPolicy) WorkerOption {
+	return func(o *workerOptions) { o.policy = policy }
+}
+
+// WorkerMaxRestarts gives up restarting after n restarts, zero means no limit
+func WorkerMaxRestarts(n int) WorkerOption {
+	return func(o *workerOptions) { o.maxRestarts = n }
+}
+
+// WorkerBackoff sets the exponential backoff between restarts
+func WorkerBackoff(min, max time.Duration) WorkerOption {
+	return func(o *workerOptions) {
+		o.minBackoff = min
+		o.maxBackoff = max
+	}
+}
+
+type worker struct {
+	lastErr  error
+	fn       WorkerFunc
+	name     string
+	state    string
+	opts     workerOptions
+	restarts int
+	lock     sync.Mutex
+}
+
+func (w *worker) setState(state string, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.state = state
+	if err != nil {
+		w.lastErr = err
+	}
+}
+
+func (w *worker) health(context.Context) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	switch w.state {
+	case workerFailed:
+		return fmt.Errorf("worker failed after %d restarts: %v", w.restarts, w.lastErr)
+	case workerBackoff:
+		return fmt.Errorf("worker is restarting (%d) after failure: %v", w.restarts, w.lastErr)
+	}
+
+	return nil
+}
+
+func (w *worker) runOnce(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			logger.Errorf("worker %s panic: %v\n%s", w.name, r, debug.Stack())
+		}
+	}()
+
+	return w.fn(ctx)
+}
+
+func (w *worker) loop(ctx context.Context) {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = w.opts.minBackoff
+	b.MaxInterval = w.opts.maxBackoff
+	b.MaxElapsedTime = 0
+
+	for {
+		w.setState(workerRunning, nil)
+		logger.Infof("worker %s is running", w.name)
+
+		begin := time.Now()
+		err := w.runOnce(ctx)
+		if ctx.Err() != nil {
+			w.setState(workerStopped, err)
+			logger.Infof("worker %s stopped", w.name)
+			return
+		}
+
+		if err != nil {
+			logger.Errorf("worker %s exited with an error: %s", w.name, err.Error())
+		}
+
+		restart := w.opts.policy == RestartAlways || (w.opts.policy == RestartOnFailure && err != nil)
+		switch {
+		case !restart && err == nil:
+			w.setState(workerStopped, nil)
+			return
+		case !restart, w.opts.maxRestarts > 0 && w.restarts >= w.opts.maxRestarts:
+			w.setState(workerFailed, err)
+			return
+		}
+
+		// the worker ran long enough, so it is not a crash loop
+		if time.Since(begin) > w.opts.maxBackoff {
+			b.Reset()
+		}
+
+		w.lock.Lock()
+		w.restarts++
+		w.lock.Unlock()
+		w.setState(workerBackoff, err)
+
+		select {
+		case <-time.After(b.NextBackOff()):
+		case <-ctx.Done():
+			w.setState(workerStopped, nil)
+			return
+		}
+	}
+}
+
+type workerGroup struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers []*worker
+	wg      sync.WaitGroup
+	lock    sync.Mutex
+}
+
+func (g *workerGroup) add(w *worker) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for _, exist := range g.workers {
+		if exist.name == w.name {
+			return fmt.Errorf("worker %s exists already", w.name)
+		}
+	}
+
+	g.workers = append(g.workers, w)
+	health.Register(workerCheckPfx+w.name, w.health, health.WithCacheTTL(0))
+
+	// the group is running, start it right now
+	if g.ctx != nil {
+		g.launch(w)
+	}
+
+	return nil
+}
+
+func (g *workerGroup) launch(w *worker) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		w.loop(g.ctx)
+	}()
+}
+
+func (g *workerGroup) start(ctx context.Context) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.ctx != nil {
+		return
+	}
+
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	for _, w := range g.workers {
+		g.launch(w)
+	}
+}
+
+// stop cancels all workers and waits for them until ctx is done
+func (g *workerGroup) stop(ctx context.Context) error {
+	g.lock.Lock()
+	if g.cancel == nil {
+		g.lock.Unlock()
+		return nil
+	}
+	g.cancel()
+	g.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workers are still running: %s", ctx.Err().Error())
+	}
+}
+
+// AddWorker runs fn in background along with the server. fn should return once ctx is done,
+// which happens after the public servers are shut down. It is restarted on failure by default.
+// Workers are ignored if the server runs in ServeModeAPI.
+func (s *Server) AddWorker(name string, fn WorkerFunc, opts ...WorkerOption) error {
+	if s.serveMode == ServeModeAPI {
+		logger.Debugf("serve mode is %s, skip worker %s", s.serveMode, name)
+		return nil
+	}
+
+	o := workerOptions{
+		policy:     RestartOnFailure,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return s.workers.add(&worker{name: name, fn: fn, opts: o, state: workerPending})
+}
@@ -0,0 +1,78 @@
+/*
+@Date: 2026/10/18 17:20
+@Author: yvanz
+@File : worker_test
+*/
+
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerRestart(t *testing.T) {
+	var calls int32
+	w := &worker{
+		name:  "flaky",
+		state: workerPending,
+		opts: workerOptions{
+			policy:      RestartOnFailure,
+			minBackoff:  time.Millisecond,
+			maxBackoff:  10 * time.Millisecond,
+			maxRestarts: 2,
+		},
+		fn: func(ctx context.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				panic("boom")
+			}
+
+			return errors.New("broken")
+		},
+	}
+
+	w.loop(context.Background())
+
+	if calls != 3 {
+		t.Errorf("want 3 calls, got %d", calls)
+	}
+	if w.state != workerFailed || w.health(context.Background()) == nil {
+		t.Errorf("worker should be failed, got %s", w.state)
+	}
+}
+
+func TestWorkerGroupStop(t *testing.T) {
+	g := workerGroup{}
+	stopped := make(chan struct{})
+
+	err := g.add(&worker{
+		name:  "loop",
+		state: workerPending,
+		opts:  workerOptions{policy: RestartNever},
+		fn: func(ctx context.Context) error {
+			<-ctx.Done()
+			close(stopped)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	g.start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = g.stop(ctx); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	select {
+	case <-stopped:
+	default:
+		t.Error("worker is not canceled")
+	}
+}
@@ -0,0 +1,56 @@
+/*
+@Date: 2026/10/19 20:40
+@Author: yvanz
+@File : reqctx
+*/
+
+package gadget
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestContext returns ctx with the deadline and cancellation of the request if ctx is a gin.Context or derived from one,
+// as gin.Context does not propagate them. ctx itself is returned if it is not or has a deadline of its own.
+// Pass it to the calls which should end with the request, such as the database and the downstream services.
+func RequestContext(ctx context.Context) context.Context {
+	if ctx == nil || ctx.Done() != nil {
+		return ctx
+	}
+
+	// gin.Context returns its request by the key 0
+	req, ok := ctx.Value(0).(*http.Request)
+	if !ok || req == nil {
+		return ctx
+	}
+
+	return requestContext{Context: ctx, req: req.Context()}
+}
+
+// requestContext takes the values from the original context first, then from the request
+type requestContext struct {
+	context.Context
+	req context.Context
+}
+
+func (c requestContext) Deadline() (time.Time, bool) {
+	return c.req.Deadline()
+}
+
+func (c requestContext) Done() <-chan struct{} {
+	return c.req.Done()
+}
+
+func (c requestContext) Err() error {
+	return c.req.Err()
+}
+
+func (c requestContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+
+	return c.req.Value(key)
+}
@@ -0,0 +1,34 @@
+/*
+@Date: 2026/10/19 21:00
+@Author: yvanz
+@File : reqctx_test
+*/
+
+package gadget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(WithRequestID(ctx, "req-1"))
+
+	reqCtx := RequestContext(context.WithValue(c, SpanCtxKey, "span")) //nolint:staticcheck
+	if reqCtx.Value(SpanCtxKey) != "span" || RequestID(reqCtx) != "req-1" {
+		t.Error("the values are lost")
+	}
+
+	cancel()
+	if reqCtx.Err() != context.Canceled {
+		t.Errorf("got %v", reqCtx.Err())
+	}
+}
@@ -0,0 +1,37 @@
+/*
+@Date: 2026/10/19 19:40
+@Author: yvanz
+@File : requestid
+*/
+
+package gadget
+
+import "context"
+
+const (
+	// RequestIDHeader carries the request ID in HTTP requests, responses and Kafka messages
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDCtxKey is the key of the request ID in gin.Context and the logs
+	RequestIDCtxKey = "request_id"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx with the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx, which could be a gin.Context or any context derived from it
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+
+	id, _ := ctx.Value(RequestIDCtxKey).(string)
+	return id
+}
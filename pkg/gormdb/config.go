@@ -21,22 +21,64 @@ import (
 )
 
 type DBConfig struct { //nolint:govet
-	ReadDBHostList  []string `yaml:"read_db_host_list" env:"MySQLReadHostList" env-description:"mysql slave host list" json:"read_db_host_list,omitempty"`
-	WriteDBHost     string   `yaml:"write_db_host" env:"MySQLWriteHost" env-description:"mysql master host" json:"write_db_host,omitempty"`
-	WriteDBUser     string   `yaml:"write_db_user" env:"MySQLWriteUser" env-description:"mysql master user" json:"write_db_user,omitempty"`
-	WriteDBPassword string   `yaml:"write_db_password" env:"MySQLWritePassword" env-description:"mysql master password" json:"write_db_password,omitempty"`
-	WriteDB         string   `yaml:"write_db" env:"MySQLWriteDB" env-description:"mysql master database" json:"write_db,omitempty"`
-	ReadDBUser      string   `yaml:"read_db_user" env:"MySQLReadUser" env-description:"mysql slave user" json:"read_db_user,omitempty"`
-	ReadDBPassword  string   `yaml:"read_db_password" env:"MySQLReadPassword" env-description:"mysql slave password" json:"read_db_password,omitempty"`
-	ReadDB          string   `yaml:"read_db" env:"MySQLReadDB" env-description:"mysql slave database" json:"read_db,omitempty"`
-	Prefix          string   `yaml:"table_prefix" json:"prefix,omitempty"`
-	LogLevel        string   `yaml:"log_level" env:"MySQLLogLevel" env-description:"log level of mysql log: silent/info/warn/error" json:"log_level,omitempty"`
-	MaxIdleConns    int      `yaml:"max_idle_conns" json:"max_idle_conns,omitempty"`
-	MaxOpenConns    int      `yaml:"max_open_conns" json:"max_open_conns,omitempty"`
-	WriteDBPort     uint16   `yaml:"write_db_port" env:"MySQLWritePort" env-description:"mysql master port" json:"write_db_port,omitempty"`
-	ReadDBPort      uint16   `yaml:"read_db_port" env:"MySQLReadPort" env-description:"mysql slave port" json:"read_db_port,omitempty"`
-	Logging         bool     `yaml:"logging" json:"logging,omitempty"`
-	RawColumn       bool     `yaml:"-" json:"raw_column,omitempty"`
+	ReadDBHostList  []string      `yaml:"read_db_host_list" env:"MySQLReadHostList" env-description:"mysql slave host list" json:"read_db_host_list,omitempty" reload:"restart"`
+	WriteDBHost     string        `yaml:"write_db_host" env:"MySQLWriteHost" env-description:"mysql master host" json:"write_db_host,omitempty" reload:"restart"`
+	WriteDBUser     string        `yaml:"write_db_user" env:"MySQLWriteUser" env-description:"mysql master user" json:"write_db_user,omitempty" reload:"restart"`
+	WriteDBPassword string        `yaml:"write_db_password" env:"MySQLWritePassword" env-description:"mysql master password" json:"write_db_password,omitempty" secret:"true" reload:"restart"`
+	WriteDB         string        `yaml:"write_db" env:"MySQLWriteDB" env-description:"mysql master database" json:"write_db,omitempty" reload:"restart"`
+	ReadDBUser      string        `yaml:"read_db_user" env:"MySQLReadUser" env-description:"mysql slave user" json:"read_db_user,omitempty" reload:"restart"`
+	ReadDBPassword  string        `yaml:"read_db_password" env:"MySQLReadPassword" env-description:"mysql slave password" json:"read_db_password,omitempty" secret:"true" reload:"restart"`
+	ReadDB          string        `yaml:"read_db" env:"MySQLReadDB" env-description:"mysql slave database" json:"read_db,omitempty" reload:"restart"`
+	Prefix          string        `yaml:"table_prefix" json:"prefix,omitempty" reload:"restart"`
+	LogLevel        string        `yaml:"log_level" env:"MySQLLogLevel" env-description:"log level of mysql log: silent/info/warn/error" json:"log_level,omitempty"`
+	SlowThreshold   time.Duration `yaml:"slow_threshold" env:"MySQLSlowThreshold" env-default:"1s" env-description:"queries slower than it are logged as slow sql" json:"slow_threshold,omitempty"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" json:"max_idle_conns,omitempty" reload:"restart"`
+	MaxOpenConns    int           `yaml:"max_open_conns" json:"max_open_conns,omitempty" reload:"restart"`
+	WriteDBPort     uint16        `yaml:"write_db_port" env:"MySQLWritePort" env-description:"mysql master port" json:"write_db_port,omitempty" reload:"restart"`
+	ReadDBPort      uint16        `yaml:"read_db_port" env:"MySQLReadPort" env-description:"mysql slave port" json:"read_db_port,omitempty" reload:"restart"`
+	Logging         bool          `yaml:"logging" json:"logging,omitempty" reload:"restart"`
+	RawColumn       bool          `yaml:"-" json:"raw_column,omitempty"`
+}
+
+// Validate returns all problems of the config
+func (c DBConfig) Validate() (errs []error) {
+	if c.WriteDBHost == "" {
+		errs = append(errs, fmt.Errorf("write_db_host is required"))
+	}
+	if c.WriteDBUser == "" {
+		errs = append(errs, fmt.Errorf("write_db_user is required"))
+	}
+	if c.WriteDBPort == 0 {
+		errs = append(errs, fmt.Errorf("write_db_port is required"))
+	}
+
+	if len(c.ReadDBHostList) > 0 {
+		if c.ReadDBUser == "" {
+			errs = append(errs, fmt.Errorf("read_db_user is required when read_db_host_list is set"))
+		}
+		if c.ReadDBPort == 0 {
+			errs = append(errs, fmt.Errorf("read_db_port is required when read_db_host_list is set"))
+		}
+	}
+
+	switch c.LogLevel {
+	case "", "silent", "info", "warn", "waring", "error":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported log_level %q, only support silent/info/warn/error", c.LogLevel))
+	}
+
+	if c.SlowThreshold < 0 {
+		errs = append(errs, fmt.Errorf("slow_threshold must not be negative"))
+	}
+
+	if c.MaxIdleConns < 0 || c.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("max_idle_conns and max_open_conns must not be negative"))
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("max_idle_conns %d is greater than max_open_conns %d", c.MaxIdleConns, c.MaxOpenConns))
+	}
+
+	return errs
 }
 
 func (c *DBConfig) initConfig() (conf *gorm.Config, err error) {
@@ -62,7 +104,8 @@ func (c *DBConfig) initConfig() (conf *gorm.Config, err error) {
 		NamingStrategy: namingStrategy,
 	}
 	if c.Logging {
-		conf.Logger = initLogger(c.LogLevel)
+		ReloadLogger(*c)
+		conf.Logger = _logger
 	}
 
 	return
@@ -74,6 +117,9 @@ func (c DBConfig) BuildMySQLClient(ctx context.Context) (*DB, error) {
 	var master *gorm.DB
 	var sqlDBMaster *sql.DB
 
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+
 	if _default != nil {
 		return _default, nil
 	}
@@ -108,8 +154,15 @@ func (c DBConfig) BuildMySQLClient(ctx context.Context) (*DB, error) {
 			continue
 		}
 
+		// keep the replica pool so that it can be pinged and closed later
 		dsn := createDSN(c.ReadDBUser, c.ReadDBPassword, host, c.ReadDB, c.ReadDBPort)
-		slaves = append(slaves, mysql.Open(dsn))
+		sqlDBSlave, e := sql.Open("mysql", dsn)
+		if e != nil {
+			return nil, e
+		}
+
+		_default.readSQL = append(_default.readSQL, sqlDBSlave)
+		slaves = append(slaves, mysql.New(mysql.Config{Conn: sqlDBSlave}))
 	}
 
 	if len(slaves) > 0 {
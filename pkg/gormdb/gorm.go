@@ -10,6 +10,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/yvanz/gin-tmpl/pkg/gadget"
@@ -19,11 +21,16 @@ import (
 
 var (
 	// 仅用于单例模式下
-	_default  *DB
-	ErrClient = errors.New("mysql client is not initialized yet")
+	_default *DB
+	// guards _default, which is built by BuildMySQLClient or BuildMockClient and cleared by Close
+	defaultLock sync.Mutex
+	ErrClient   = errors.New("mysql client is not initialized yet")
 )
 
 func GetDB() *DB {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+
 	if _default == nil {
 		return &DB{}
 	}
@@ -39,11 +46,12 @@ func Cli(ctx context.Context) *gorm.DB {
 type DB struct {
 	db       *gorm.DB
 	writeSQL *sql.DB
+	readSQL  []*sql.DB
 	mock     sqlmock.Sqlmock
 	ctx      context.Context
 }
 
-// Master check *gorm.DB if is nil
+// Master check *gorm.DB if is nil, the statements end with the request if ctx is a gin.Context, see gadget.RequestContext
 func (d *DB) Master(ctx context.Context) *gorm.DB {
 	if d == nil {
 		return nil
@@ -51,10 +59,15 @@ func (d *DB) Master(ctx context.Context) *gorm.DB {
 
 	spanCtx, err := gadget.ExtractTraceSpan(ctx)
 	if err != nil {
-		return d.db
+		if ctx == nil {
+			return d.db
+		}
+
+		// the sql logs carry the request ID of ctx even without tracing
+		return d.db.WithContext(gadget.RequestContext(ctx))
 	}
 
-	return d.db.WithContext(spanCtx)
+	return d.db.WithContext(gadget.RequestContext(spanCtx))
 }
 
 func (d *DB) Migration(dst ...interface{}) error {
@@ -65,14 +78,46 @@ func (d *DB) Migration(dst ...interface{}) error {
 	return d.db.Clauses(dbresolver.Write).AutoMigrate(dst...)
 }
 
+// Ping checks the connection of master and every replica
+func (d *DB) Ping(ctx context.Context) error {
+	if d == nil || d.writeSQL == nil {
+		return ErrClient
+	}
+
+	if err := d.writeSQL.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping mysql master failed: %s", err.Error())
+	}
+
+	for i, replica := range d.readSQL {
+		if err := replica.PingContext(ctx); err != nil {
+			return fmt.Errorf("ping mysql replica %d failed: %s", i, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Close closes the master and the replicas, the default instance is cleared so that it can be built again
 func (d *DB) Close() (err error) {
 	if d == nil {
 		return nil
 	}
 
+	defaultLock.Lock()
+	if d == _default {
+		_default = nil
+	}
+	defaultLock.Unlock()
+
 	if d.db != nil {
 		err = d.writeSQL.Close()
 	}
 
+	for _, replica := range d.readSQL {
+		if e := replica.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
 	return
 }
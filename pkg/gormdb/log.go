@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/yvanz/gin-tmpl/pkg/logger"
@@ -18,6 +19,8 @@ import (
 	"gorm.io/gorm/utils"
 )
 
+var _logger = &liveLogger{}
+
 type DBLog struct {
 	logg.Config
 }
@@ -88,7 +91,7 @@ func (d DBLog) Trace(ctx context.Context, begin time.Time, fc func() (sql string
 	}
 }
 
-func initLogger(level string) logg.Interface {
+func initLogger(level string, slowThreshold time.Duration) *DBLog {
 	var logLevel logg.LogLevel
 	switch level {
 	case "silent":
@@ -103,8 +106,12 @@ func initLogger(level string) logg.Interface {
 		logLevel = logg.Silent
 	}
 
+	if slowThreshold <= 0 {
+		slowThreshold = time.Second
+	}
+
 	config := logg.Config{
-		SlowThreshold:             time.Second,
+		SlowThreshold:             slowThreshold,
 		LogLevel:                  logLevel,
 		IgnoreRecordNotFoundError: true,
 		Colorful:                  false,
@@ -114,3 +121,38 @@ func initLogger(level string) logg.Interface {
 		Config: config,
 	}
 }
+
+// liveLogger is shared by the clients, it delegates to the DBLog stored in it which could be replaced at any time
+type liveLogger struct {
+	v atomic.Value
+}
+
+func (l *liveLogger) get() *DBLog {
+	return l.v.Load().(*DBLog)
+}
+
+func (l *liveLogger) LogMode(level logg.LogLevel) logg.Interface {
+	return l.get().LogMode(level)
+}
+
+func (l *liveLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Info(ctx, msg, data...)
+}
+
+func (l *liveLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Warn(ctx, msg, data...)
+}
+
+func (l *liveLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Error(ctx, msg, data...)
+}
+
+func (l *liveLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	l.get().Trace(ctx, begin, fc, err)
+}
+
+// ReloadLogger changes log_level and slow_threshold of the mysql client on the fly,
+// it takes no effect if logging is disabled
+func ReloadLogger(c DBConfig) {
+	_logger.v.Store(initLogger(c.LogLevel, c.SlowThreshold))
+}
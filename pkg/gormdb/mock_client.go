@@ -22,6 +22,9 @@ func (c DBConfig) BuildMockClient() (err error) {
 	var master *gorm.DB
 	var sqlDBMaster *sql.DB
 
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+
 	if _default != nil {
 		return nil
 	}
@@ -54,6 +57,9 @@ func (c DBConfig) BuildMockClient() (err error) {
 
 // GetMock export sqlmock
 func GetMock() (mock sqlmock.Sqlmock, err error) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+
 	if _default == nil {
 		err = fmt.Errorf("please BuildMockClient")
 		return
@@ -0,0 +1,178 @@
+/*
+@Date: 2026/10/19 09:30
+@Author: yvanz
+@File : interceptor
+*/
+
+package grpcserver
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryRecovery turns panics of handlers into codes.Internal
+func UnaryRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery turns panics of handlers into codes.Internal
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, method string, r interface{}) error {
+	logger.ErrorfWithTrace(ctx, "grpc %s panic: %v\n%s", method, r, debug.Stack())
+	return status.Errorf(codes.Internal, "panic: %v", r)
+}
+
+// UnaryLogger logs every call with its code and latency
+func UnaryLogger() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamLogger logs every stream with its code and latency
+func StreamLogger() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	var client string
+	if p, ok := peer.FromContext(ctx); ok {
+		client = p.Addr.String()
+	}
+
+	code := status.Code(err)
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		logger.InfofWithTrace(ctx, "grpc %s - %s %s %v", client, method, code, time.Since(start))
+	default:
+		logger.ErrorfWithTrace(ctx, "grpc %s - %s %s %v: %s", client, method, code, time.Since(start), status.Convert(err).Message())
+	}
+}
+
+// UnaryTracing starts a span for every call, as a child of the span in the incoming metadata if any.
+// Nothing is traced if tra is nil.
+func UnaryTracing(tra opentracing.Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if tra == nil {
+			return handler(ctx, req)
+		}
+
+		span, ctx := startSpan(ctx, tra, info.FullMethod)
+		defer span.Finish()
+
+		resp, err := handler(ctx, req)
+		finishSpan(span, err)
+
+		return resp, err
+	}
+}
+
+// StreamTracing is the same as UnaryTracing for streams
+func StreamTracing(tra opentracing.Tracer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if tra == nil {
+			return handler(srv, ss)
+		}
+
+		span, ctx := startSpan(ss.Context(), tra, info.FullMethod)
+		defer span.Finish()
+
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		finishSpan(span, err)
+
+		return err
+	}
+}
+
+func startSpan(ctx context.Context, tra opentracing.Tracer, method string) (opentracing.Span, context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	opts := []opentracing.StartSpanOption{ext.SpanKindRPCServer, opentracing.Tag{Key: string(ext.Component), Value: "gRPC"}}
+	if parent, err := tra.Extract(opentracing.HTTPHeaders, metadataCarrier(md)); err == nil {
+		opts = append(opts, opentracing.ChildOf(parent))
+	}
+
+	span := tra.StartSpan(method, opts...)
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+func finishSpan(span opentracing.Span, err error) {
+	code := status.Code(err)
+	span.SetTag("grpc.code", code.String())
+	if code != codes.OK {
+		ext.Error.Set(span, true)
+		span.LogFields(log.String("message", status.Convert(err).Message()))
+	}
+}
+
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier reads and writes span contexts in gRPC metadata, the same format as opentracing.HTTPHeaders
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Set(key, val string) {
+	key = strings.ToLower(key)
+	m[key] = append(m[key], val)
+}
+
+func (m metadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, values := range m {
+		for _, v := range values {
+			if err := handler(k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
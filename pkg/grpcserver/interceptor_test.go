@@ -0,0 +1,69 @@
+/*
+@Date: 2026/10/19 10:05
+@Author: yvanz
+@File : interceptor_test
+*/
+
+package grpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryRecovery(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Panic"}
+	_, err := UnaryRecovery()(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		panic("boom")
+	})
+
+	if status.Code(err) != codes.Internal {
+		t.Errorf("want codes.Internal, got %v", err)
+	}
+}
+
+func TestUnaryTracing(t *testing.T) {
+	tra := mocktracer.New()
+
+	parent := tra.StartSpan("client")
+	md := metadata.MD{}
+	if err := tra.Inject(parent.Context(), opentracing.HTTPHeaders, metadataCarrier(md)); err != nil {
+		t.Fatal(err.Error())
+	}
+	parent.Finish()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	_, err := UnaryTracing(tra)(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		if opentracing.SpanFromContext(ctx) == nil {
+			t.Error("span should be in the context of handlers")
+		}
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("want codes.NotFound, got %v", err)
+	}
+
+	spans := tra.FinishedSpans()
+	if len(spans) != 2 {
+		t.Fatalf("want 2 finished spans, got %d", len(spans))
+	}
+
+	span := spans[1]
+	if span.OperationName != info.FullMethod {
+		t.Errorf("want operation %s, got %s", info.FullMethod, span.OperationName)
+	}
+	if span.ParentID != parent.Context().(mocktracer.MockSpanContext).SpanID {
+		t.Error("span should be a child of the span in metadata")
+	}
+	if span.Tag("grpc.code") != codes.NotFound.String() || span.Tag("error") != true {
+		t.Errorf("unexpected tags %v", span.Tags())
+	}
+}
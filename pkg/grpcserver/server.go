@@ -0,0 +1,85 @@
+/*
+@Date: 2026/10/19 09:10
+@Author: yvanz
+@File : server
+*/
+
+package grpcserver
+
+import (
+	"context"
+
+	prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server is a grpc.Server with metrics, tracing, logging and recovery interceptors,
+// the health service and the reflection service
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// New returns a Server, register is called to register services before it serves.
+// opts are appended to the built-in options, interceptors in opts run after the built-in ones.
+func New(tra opentracing.Tracer, register func(opentracing.Tracer, *grpc.Server), opts ...grpc.ServerOption) *Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			prometheus.UnaryServerInterceptor,
+			UnaryTracing(tra),
+			UnaryLogger(),
+			UnaryRecovery(),
+		),
+		grpc.ChainStreamInterceptor(
+			prometheus.StreamServerInterceptor,
+			StreamTracing(tra),
+			StreamLogger(),
+			StreamRecovery(),
+		),
+	}, opts...)
+
+	s := &Server{Server: grpc.NewServer(opts...), health: health.NewServer()}
+	healthpb.RegisterHealthServer(s.Server, s.health)
+	reflection.Register(s.Server)
+
+	if register != nil {
+		register(tra, s.Server)
+	}
+
+	// metrics of every method are initialized to zero, so they must be registered after all services
+	prometheus.Register(s.Server)
+
+	return s
+}
+
+// Serving marks all services serving in the health service
+func (s *Server) Serving() {
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	for name := range s.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+}
+
+// Shutdown marks all services not serving, then waits for pending calls until ctx is done.
+// The remaining calls are canceled if ctx is done first.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
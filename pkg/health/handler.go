@@ -0,0 +1,59 @@
+/*
+@Date: 2026/10/18 10:40
+@Author: yvanz
+@File : handler
+*/
+
+package health
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Wrap(router *gin.Engine) {
+	WrapGroup(&router.RouterGroup)
+}
+
+func WrapGroup(router *gin.RouterGroup) {
+	WrapRegistry(router, _default)
+}
+
+// WrapRegistry registers /healthz and /readyz backed by registry
+func WrapRegistry(router *gin.RouterGroup, registry *Registry) {
+	routers := []struct {
+		Handler gin.HandlerFunc
+		Method  string
+		Path    string
+	}{
+		{LivenessHandler(registry), "GET", "/healthz"},
+		{ReadinessHandler(registry), "GET", "/readyz"},
+	}
+
+	for _, r := range routers {
+		router.Handle(r.Method, r.Path, r.Handler)
+	}
+}
+
+func LivenessHandler(registry *Registry) gin.HandlerFunc {
+	return reportHandler(registry.Liveness)
+}
+
+func ReadinessHandler(registry *Registry) gin.HandlerFunc {
+	return reportHandler(registry.Readiness)
+}
+
+func reportHandler(run func(ctx context.Context) Report) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := run(c.Request.Context())
+
+		code := http.StatusOK
+		if report.Status != StatusUp {
+			code = http.StatusServiceUnavailable
+		}
+
+		c.JSON(code, report)
+	}
+}
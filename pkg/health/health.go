@@ -0,0 +1,234 @@
+/*
+@Date: 2026/10/18 10:12
+@Author: yvanz
+@File : health
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout  = 3 * time.Second
+	defaultCacheTTL = 5 * time.Second
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// CheckFunc probes a dependency, a nil error means the dependency is healthy
+type CheckFunc func(ctx context.Context) error
+
+type Result struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  int64     `json:"duration_ms"`
+	Cached    bool      `json:"cached"`
+}
+
+type Report struct {
+	Components map[string]Result `json:"components"`
+	Status     Status            `json:"status"`
+}
+
+type checkOptions struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	liveness bool
+}
+
+type CheckOption func(*checkOptions)
+
+// WithTimeout limits how long a single run of the check can take
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(o *checkOptions) { o.timeout = timeout }
+}
+
+// WithCacheTTL reuses the last result of the check within ttl, zero disables caching
+func WithCacheTTL(ttl time.Duration) CheckOption {
+	return func(o *checkOptions) { o.cacheTTL = ttl }
+}
+
+// Liveness marks the check as a liveness check, it will be run by both /healthz and /readyz.
+// Checks without this option only take part in readiness.
+func Liveness() CheckOption {
+	return func(o *checkOptions) { o.liveness = true }
+}
+
+type checker struct {
+	last  Result
+	check CheckFunc
+	name  string
+	opts  checkOptions
+	lock  sync.Mutex
+}
+
+func (c *checker) run(ctx context.Context) Result {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.opts.cacheTTL > 0 && !c.last.CheckedAt.IsZero() && time.Since(c.last.CheckedAt) < c.opts.cacheTTL {
+		res := c.last
+		res.Cached = true
+		return res
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
+
+	begin := time.Now()
+	// some clients ignore ctx, so never wait for them longer than the timeout
+	errChan := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errChan <- fmt.Errorf("check panic: %v", r)
+			}
+		}()
+
+		errChan <- c.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %v", c.opts.timeout)
+	}
+
+	res := Result{
+		Name:      c.name,
+		Status:    StatusUp,
+		CheckedAt: time.Now(),
+		Duration:  time.Since(begin).Milliseconds(),
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+
+	c.last = res
+	return res
+}
+
+type Registry struct {
+	checkers map[string]*checker
+	lock     sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{checkers: make(map[string]*checker)}
+}
+
+// Register adds a check named name, a check with the same name will be replaced
+func (r *Registry) Register(name string, fn CheckFunc, opts ...CheckOption) {
+	o := checkOptions{
+		timeout:  defaultTimeout,
+		cacheTTL: defaultCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.checkers[name] = &checker{name: name, check: fn, opts: o}
+}
+
+func (r *Registry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.checkers, name)
+}
+
+// Names returns the names of all registered checks in order
+func (r *Registry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	names := make([]string, 0, len(r.checkers))
+	for name := range r.checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Liveness runs the checks registered with Liveness
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return r.runChecks(ctx, true)
+}
+
+// Readiness runs all registered checks
+func (r *Registry) Readiness(ctx context.Context) Report {
+	return r.runChecks(ctx, false)
+}
+
+func (r *Registry) runChecks(ctx context.Context, livenessOnly bool) Report {
+	r.lock.RLock()
+	checkers := make([]*checker, 0, len(r.checkers))
+	for _, c := range r.checkers {
+		if livenessOnly && !c.opts.liveness {
+			continue
+		}
+
+		checkers = append(checkers, c)
+	}
+	r.lock.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i := range checkers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = checkers[i].run(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]Result, len(results)),
+	}
+	for _, res := range results {
+		if res.Status != StatusUp {
+			report.Status = StatusDown
+		}
+
+		report.Components[res.Name] = res
+	}
+
+	return report
+}
+
+var _default = NewRegistry()
+
+func Default() *Registry {
+	return _default
+}
+
+// Register adds a check to the default registry
+func Register(name string, fn CheckFunc, opts ...CheckOption) {
+	_default.Register(name, fn, opts...)
+}
+
+// Unregister removes a check from the default registry
+func Unregister(name string) {
+	_default.Unregister(name)
+}
@@ -0,0 +1,84 @@
+/*
+@Date: 2026/10/18 11:05
+@Author: yvanz
+@File : health_test
+*/
+
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	calls := 0
+	r.Register("live", func(ctx context.Context) error {
+		calls++
+		return nil
+	}, Liveness())
+	r.Register("broken", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}, WithCacheTTL(0))
+	r.Register("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, WithTimeout(50*time.Millisecond))
+
+	live := r.Liveness(context.Background())
+	if live.Status != StatusUp || len(live.Components) != 1 {
+		t.Fatalf("unexpected liveness report: %+v", live)
+	}
+
+	ready := r.Readiness(context.Background())
+	if ready.Status != StatusDown {
+		t.Fatalf("readiness should be down: %+v", ready)
+	}
+	if ready.Components["broken"].Error != "connection refused" {
+		t.Errorf("unexpected error of broken check: %q", ready.Components["broken"].Error)
+	}
+	if ready.Components["slow"].Status != StatusDown {
+		t.Error("slow check should time out")
+	}
+	if !ready.Components["live"].Cached || calls != 1 {
+		t.Errorf("live check should be cached, called %d times", calls)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := NewRegistry()
+	r.Register("db", func(ctx context.Context) error { return errors.New("down") })
+
+	g := gin.New()
+	WrapRegistry(&g.RouterGroup, r)
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{path: "/healthz", code: http.StatusOK},
+		{path: "/readyz", code: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			g.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("want %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
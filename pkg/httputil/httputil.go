@@ -29,6 +29,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
@@ -281,7 +282,6 @@ func Send(method, rawurl string, options ...SendOption) (*http.Response, error)
 		headers:              map[string]string{},
 		retry:                retryOptions{backoff: &backoff.StopBackOff{}},
 		transport:            nil, // Use HTTP default.
-		ctx:                  context.Background(),
 		url:                  u,
 		httpFallbackDisabled: false,
 	}
@@ -439,10 +439,24 @@ func newRequest(method string, opts *sendOptions) (*http.Request, error) {
 	if err != nil {
 		return nil, fmt.Errorf("new request: %s", err)
 	}
-	req = req.WithContext(opts.ctx)
+	// the request ends with the one being served, whose context is either of them
+	ctx := opts.ctx
+	if ctx == nil {
+		ctx = opts.spanCtx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req = req.WithContext(gadget.RequestContext(ctx))
 	if opts.body == nil {
 		req.ContentLength = 0
 	}
+	for _, c := range []context.Context{opts.ctx, opts.spanCtx} {
+		if id := gadget.RequestID(c); id != "" {
+			req.Header.Set(gadget.RequestIDHeader, id)
+			break
+		}
+	}
 	for key, val := range opts.headers {
 		req.Header.Set(key, val)
 	}
@@ -0,0 +1,74 @@
+/*
+@Date: 2026/10/19 14:00
+@Author: yvanz
+@File : config
+*/
+
+package jwtauth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Algorithms    []string      `yaml:"algorithms" env:"JWTAlgorithms" env-default:"RS256,ES256" env-description:"accepted signing algorithms: RS256/384/512, PS256/384/512, ES256/384/512, HS256/384/512" json:"algorithms,omitempty"`
+	Audience      []string      `yaml:"audience" env:"JWTAudience" env-description:"accepted audiences, the aud claim must contain one of them if set" json:"audience,omitempty"`
+	Issuer        string        `yaml:"issuer" env:"JWTIssuer" env-description:"the iss claim must equal it if set" json:"issuer,omitempty"`
+	JWKSFile      string        `yaml:"jwks_file" env:"JWTJWKSFile" env-description:"local JWKS file of the verification keys" json:"jwks_file,omitempty"`
+	JWKSURL       string        `yaml:"jwks_url" env:"JWTJWKSURL" env-description:"JWKS URL of the verification keys, e.g. jwks_uri of an OIDC provider" json:"jwks_url,omitempty"`
+	HMACSecret    string        `yaml:"hmac_secret" env:"JWTHMACSecret" env-description:"shared secret of HS algorithms" json:"hmac_secret,omitempty" secret:"true"`
+	OperatorClaim string        `yaml:"operator_claim" env:"JWTOperatorClaim" env-default:"sub" env-description:"claim used as the operator in logs" json:"operator_claim,omitempty"`
+	JWKSRefresh   time.Duration `yaml:"jwks_refresh" env:"JWTJWKSRefresh" env-default:"1h" env-description:"how often to reload the JWKS, it is also reloaded on unknown key ids" json:"jwks_refresh,omitempty"`
+	Leeway        time.Duration `yaml:"leeway" env:"JWTLeeway" env-default:"1m" env-description:"clock skew allowed when checking exp, nbf and iat" json:"leeway,omitempty"`
+}
+
+// Enabled reports whether any verification key is configured
+func (c Config) Enabled() bool {
+	return c.JWKSFile != "" || c.JWKSURL != "" || c.HMACSecret != ""
+}
+
+// Validate returns all problems of the config
+func (c Config) Validate() (errs []error) {
+	if len(c.Algorithms) == 0 {
+		errs = append(errs, fmt.Errorf("algorithms is required"))
+	}
+
+	var hmacAlg, keyAlg bool
+	for _, alg := range c.Algorithms {
+		if _, ok := algorithms[alg]; !ok {
+			errs = append(errs, fmt.Errorf("unsupported algorithm %q, only support %s", alg, strings.Join(supportedAlgorithms(), "/")))
+			continue
+		}
+
+		if strings.HasPrefix(alg, "HS") {
+			hmacAlg = true
+		} else {
+			keyAlg = true
+		}
+	}
+
+	if keyAlg && c.JWKSFile == "" && c.JWKSURL == "" {
+		errs = append(errs, fmt.Errorf("jwks_file or jwks_url is required by RS, PS and ES algorithms"))
+	}
+	if hmacAlg && c.HMACSecret == "" && c.JWKSFile == "" && c.JWKSURL == "" {
+		errs = append(errs, fmt.Errorf("hmac_secret, jwks_file or jwks_url is required by HS algorithms"))
+	}
+	if c.JWKSFile != "" && c.JWKSURL != "" {
+		errs = append(errs, fmt.Errorf("jwks_file and jwks_url must not be set together"))
+	}
+
+	if c.JWKSURL != "" {
+		if u, err := url.Parse(c.JWKSURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			errs = append(errs, fmt.Errorf("invalid jwks_url %s", c.JWKSURL))
+		}
+	}
+
+	if c.JWKSRefresh < 0 || c.Leeway < 0 {
+		errs = append(errs, fmt.Errorf("jwks_refresh and leeway must not be negative"))
+	}
+
+	return errs
+}
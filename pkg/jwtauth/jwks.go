@@ -0,0 +1,276 @@
+/*
+@Date: 2026/10/19 14:20
+@Author: yvanz
+@File : jwks
+*/
+
+package jwtauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+const (
+	defaultJWKSRefresh = time.Hour
+	// unknown key ids reload the JWKS at most once in this interval, so that forged tokens could not flood the provider
+	jwksMissInterval = 30 * time.Second
+	jwksFetchTimeout = 10 * time.Second
+	jwksMaxSize      = 1 << 20
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+type key struct {
+	// *rsa.PublicKey, *ecdsa.PublicKey or []byte
+	value interface{}
+	kid   string
+	alg   string
+}
+
+type keySet struct {
+	keys []key
+}
+
+func parseJWKS(data []byte) (*keySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %s", err.Error())
+	}
+
+	set := &keySet{}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		value, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d (kid %q) in JWKS: %s", i, k.Kid, err.Error())
+		}
+
+		set.keys = append(set.keys, key{value: value, kid: k.Kid, alg: k.Alg})
+	}
+
+	return set, nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent is too large")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported kty %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer %q", s)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// candidates returns the keys which could verify a token signed by alg with kid, all keys of the type if kid is empty
+func (s *keySet) candidates(kid, alg string) []interface{} {
+	var keys []interface{}
+	for _, k := range s.keys {
+		if (kid != "" && k.kid != kid) || (k.alg != "" && k.alg != alg) {
+			continue
+		}
+
+		if algorithms[alg].accepts(k.value) {
+			keys = append(keys, k.value)
+		}
+	}
+
+	return keys
+}
+
+func (s *keySet) has(kid string) bool {
+	for _, k := range s.keys {
+		if k.kid == kid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jwksSource loads the key set from a file or a URL and caches it.
+// It is reloaded every refresh, or on an unknown key id after the provider rotates keys.
+// The cached key set is kept if reloading fails.
+type jwksSource struct {
+	set      *keySet
+	client   *http.Client
+	file     string
+	url      string
+	loadedAt time.Time
+	missedAt time.Time
+	modTime  time.Time
+	refresh  time.Duration
+	lock     sync.Mutex
+}
+
+func newJWKSSource(c Config) *jwksSource {
+	refresh := c.JWKSRefresh
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+
+	return &jwksSource{
+		file:    c.JWKSFile,
+		url:     c.JWKSURL,
+		refresh: refresh,
+		client:  &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+func (s *jwksSource) keys(ctx context.Context, kid string) (*keySet, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reload := s.set == nil || time.Since(s.loadedAt) > s.refresh
+	if !reload && kid != "" && !s.set.has(kid) && time.Since(s.missedAt) > jwksMissInterval {
+		s.missedAt = time.Now()
+		reload = true
+	}
+
+	if reload {
+		if err := s.load(ctx); err != nil {
+			if s.set == nil {
+				return nil, err
+			}
+
+			// retry later rather than on every request while the provider is down
+			s.loadedAt = time.Now().Add(jwksMissInterval - s.refresh)
+			logger.Warnf("reload JWKS failed, keep the cached keys: %s", err.Error())
+		}
+	}
+
+	return s.set, nil
+}
+
+// load must be called with the lock held
+func (s *jwksSource) load(ctx context.Context) error {
+	data, err := s.read(ctx)
+	if err != nil || data == nil {
+		return err
+	}
+
+	set, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.set = set
+	s.loadedAt = time.Now()
+	return nil
+}
+
+// read returns nil if the file is not modified since last read
+func (s *jwksSource) read(ctx context.Context) ([]byte, error) {
+	if s.file != "" {
+		fi, err := os.Stat(s.file)
+		if err != nil {
+			return nil, err
+		}
+		if s.set != nil && fi.ModTime().Equal(s.modTime) {
+			s.loadedAt = time.Now()
+			return nil, nil
+		}
+
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return nil, err
+		}
+		s.modTime = fi.ModTime()
+
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS failed: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS failed: %s returns %d", s.url, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, jwksMaxSize))
+}
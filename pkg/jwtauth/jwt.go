@@ -0,0 +1,364 @@
+/*
+@Date: 2026/10/19 14:50
+@Author: yvanz
+@File : jwt
+*/
+
+package jwtauth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // hash functions of the algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrTokenMissing   = errors.New("bearer token is missing")
+	ErrTokenMalformed = errors.New("token is malformed")
+	ErrTokenSignature = errors.New("token signature is invalid")
+	ErrTokenExpired   = errors.New("token is expired")
+	ErrTokenNoExpiry  = errors.New("token expiry is missing")
+	ErrTokenNotValid  = errors.New("token is not valid yet")
+	ErrTokenIssuer    = errors.New("token issuer is not accepted")
+	ErrTokenAudience  = errors.New("token audience is not accepted")
+	ErrNotConfigured  = errors.New("authentication is not configured")
+
+	_default atomic.Value
+)
+
+type algorithm struct {
+	hash   crypto.Hash
+	family string
+}
+
+var algorithms = map[string]algorithm{
+	"RS256": {crypto.SHA256, "RS"}, "RS384": {crypto.SHA384, "RS"}, "RS512": {crypto.SHA512, "RS"},
+	"PS256": {crypto.SHA256, "PS"}, "PS384": {crypto.SHA384, "PS"}, "PS512": {crypto.SHA512, "PS"},
+	"ES256": {crypto.SHA256, "ES"}, "ES384": {crypto.SHA384, "ES"}, "ES512": {crypto.SHA512, "ES"},
+	"HS256": {crypto.SHA256, "HS"}, "HS384": {crypto.SHA384, "HS"}, "HS512": {crypto.SHA512, "HS"},
+}
+
+func supportedAlgorithms() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (a algorithm) accepts(k interface{}) bool {
+	switch k.(type) {
+	case *rsa.PublicKey:
+		return a.family == "RS" || a.family == "PS"
+	case *ecdsa.PublicKey:
+		return a.family == "ES"
+	case []byte:
+		return a.family == "HS"
+	default:
+		return false
+	}
+}
+
+func (a algorithm) verify(k interface{}, signingInput, sig []byte) bool {
+	h := a.hash.New()
+	h.Write(signingInput)
+	digest := h.Sum(nil)
+
+	switch a.family {
+	case "RS":
+		return rsa.VerifyPKCS1v15(k.(*rsa.PublicKey), a.hash, digest, sig) == nil
+	case "PS":
+		return rsa.VerifyPSS(k.(*rsa.PublicKey), a.hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+	case "ES":
+		pub := k.(*ecdsa.PublicKey)
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(pub, digest, r, s)
+	case "HS":
+		mac := hmac.New(a.hash.New, k.([]byte))
+		mac.Write(signingInput)
+		return hmac.Equal(mac.Sum(nil), sig)
+	default:
+		return false
+	}
+}
+
+// Claims of a verified token, Raw has all claims with numbers as json.Number
+type Claims struct {
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	Raw       map[string]interface{}
+	Subject   string
+	Issuer    string
+	// Operator is the value of the operator_claim, the subject by default
+	Operator string
+	Audience []string
+}
+
+// String returns the claim name if it is a string
+func (c *Claims) String(name string) string {
+	s, _ := c.Raw[name].(string)
+	return s
+}
+
+// Verifier verifies bearer JWTs against the keys and the claims of Config
+type Verifier struct {
+	jwks       *jwksSource
+	algorithms map[string]bool
+	audience   map[string]bool
+	hmacSecret []byte
+	issuer     string
+	operator   string
+	leeway     time.Duration
+}
+
+// NewVerifier returns a Verifier of c, the JWKS is loaded on the first verification
+func NewVerifier(c Config) (*Verifier, error) {
+	if errs := c.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	v := &Verifier{
+		algorithms: make(map[string]bool),
+		audience:   make(map[string]bool),
+		issuer:     c.Issuer,
+		operator:   c.OperatorClaim,
+		leeway:     c.Leeway,
+	}
+	for _, alg := range c.Algorithms {
+		v.algorithms[alg] = true
+	}
+	for _, aud := range c.Audience {
+		v.audience[aud] = true
+	}
+	if c.HMACSecret != "" {
+		v.hmacSecret = []byte(c.HMACSecret)
+	}
+	if c.JWKSFile != "" || c.JWKSURL != "" {
+		v.jwks = newJWKSSource(c)
+	}
+	if v.operator == "" {
+		v.operator = "sub"
+	}
+
+	return v, nil
+}
+
+// Init builds the default Verifier of c and loads the JWKS
+func Init(ctx context.Context, c Config) error {
+	v, err := NewVerifier(c)
+	if err != nil {
+		return err
+	}
+
+	if err = v.Ping(ctx); err != nil {
+		return err
+	}
+
+	_default.Store(v)
+	return nil
+}
+
+// Default returns the Verifier built by Init, or nil
+func Default() *Verifier {
+	v, _ := _default.Load().(*Verifier)
+	return v
+}
+
+// Ping loads the JWKS if it is not cached
+func (v *Verifier) Ping(ctx context.Context) error {
+	if v.jwks == nil {
+		return nil
+	}
+
+	_, err := v.jwks.keys(ctx, "")
+	return err
+}
+
+// Verify checks the signature and the claims of token, and returns the claims
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if !v.algorithms[header.Alg] {
+		return nil, fmt.Errorf("%w: algorithm %q is not accepted", ErrTokenSignature, header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	keys, err := v.keys(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, signingInput, verified := algorithms[header.Alg], []byte(parts[0]+"."+parts[1]), false
+	for _, k := range keys {
+		if verified = alg.verify(k, signingInput, sig); verified {
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrTokenSignature
+	}
+
+	raw := make(map[string]interface{})
+	if err = decodeSegment(parts[1], &raw); err != nil {
+		return nil, err
+	}
+
+	claims, err := newClaims(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err = v.validate(claims); err != nil {
+		return nil, err
+	}
+
+	claims.Operator = claims.String(v.operator)
+	if claims.Operator == "" {
+		claims.Operator = claims.Subject
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) keys(ctx context.Context, kid, alg string) ([]interface{}, error) {
+	if algorithms[alg].family == "HS" && v.hmacSecret != nil {
+		return []interface{}{v.hmacSecret}, nil
+	}
+
+	if v.jwks == nil {
+		return nil, fmt.Errorf("%w: no key for algorithm %s", ErrTokenSignature, alg)
+	}
+
+	set, err := v.jwks.keys(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return set.candidates(kid, alg), nil
+}
+
+func (v *Verifier) validate(c *Claims) error {
+	now := time.Now()
+	// a token without exp would be valid forever once leaked
+	if c.ExpiresAt.IsZero() {
+		return ErrTokenNoExpiry
+	}
+	if now.After(c.ExpiresAt.Add(v.leeway)) {
+		return ErrTokenExpired
+	}
+	if !c.NotBefore.IsZero() && now.Add(v.leeway).Before(c.NotBefore) {
+		return ErrTokenNotValid
+	}
+	if !c.IssuedAt.IsZero() && now.Add(v.leeway).Before(c.IssuedAt) {
+		return ErrTokenNotValid
+	}
+
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return ErrTokenIssuer
+	}
+
+	if len(v.audience) > 0 {
+		for _, aud := range c.Audience {
+			if v.audience[aud] {
+				return nil
+			}
+		}
+
+		return ErrTokenAudience
+	}
+
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return ErrTokenMalformed
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(v); err != nil {
+		return ErrTokenMalformed
+	}
+
+	return nil
+}
+
+func newClaims(raw map[string]interface{}) (*Claims, error) {
+	c := &Claims{Raw: raw}
+	c.Subject, _ = raw["sub"].(string)
+	c.Issuer, _ = raw["iss"].(string)
+
+	switch aud := raw["aud"].(type) {
+	case nil:
+	case string:
+		c.Audience = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			s, ok := a.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid aud", ErrTokenMalformed)
+			}
+			c.Audience = append(c.Audience, s)
+		}
+	default:
+		return nil, fmt.Errorf("%w: invalid aud", ErrTokenMalformed)
+	}
+
+	for name, t := range map[string]*time.Time{"exp": &c.ExpiresAt, "nbf": &c.NotBefore, "iat": &c.IssuedAt} {
+		n, ok := raw[name]
+		if !ok {
+			continue
+		}
+
+		num, ok := n.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid %s", ErrTokenMalformed, name)
+		}
+		f, err := num.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s", ErrTokenMalformed, name)
+		}
+
+		sec := int64(f)
+		*t = time.Unix(sec, int64((f-float64(sec))*1e9))
+	}
+
+	return c, nil
+}
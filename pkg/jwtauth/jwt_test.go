@@ -0,0 +1,176 @@
+/*
+@Date: 2026/10/19 16:10
+@Author: yvanz
+@File : jwt_test
+*/
+
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func sign(t *testing.T, alg, kid string, k interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := b64(header) + "." + b64(payload)
+
+	a := algorithms[alg]
+	h := a.hash.New()
+	h.Write([]byte(input))
+	digest := h.Sum(nil)
+
+	var sig []byte
+	var err error
+	switch key := k.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case []byte:
+		mac := hmac.New(a.hash.New, key)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	}
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return input + "." + b64(sig)
+}
+
+func writeJWKS(t *testing.T, file string, keys ...map[string]string) {
+	data, _ := json.Marshal(map[string]interface{}{"keys": keys})
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func rsaJWK(kid string, k *rsa.PrivateKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, file, rsaJWK("rsa-1", rsaKey), map[string]string{
+		"kty": "EC", "kid": "ec-1", "crv": "P-256",
+		"x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+	})
+
+	v, err := NewVerifier(Config{
+		Algorithms:    []string{"RS256", "ES256"},
+		Audience:      []string{"gin-demo"},
+		Issuer:        "https://idp.example.com",
+		JWKSFile:      file,
+		OperatorClaim: "preferred_username",
+		Leeway:        time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	now := time.Now().Unix()
+	valid := map[string]interface{}{
+		"sub": "u-1", "preferred_username": "alice", "iss": "https://idp.example.com",
+		"aud": []string{"other", "gin-demo"}, "exp": now + 60, "iat": now,
+	}
+	with := func(k string, val interface{}) map[string]interface{} {
+		c := make(map[string]interface{}, len(valid))
+		for name, v := range valid {
+			c[name] = v
+		}
+		c[k] = val
+		return c
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		err   error
+		name  string
+		token string
+	}{
+		{nil, "rsa", sign(t, "RS256", "rsa-1", rsaKey, valid)},
+		{nil, "ec", sign(t, "ES256", "ec-1", ecKey, valid)},
+		{nil, "within leeway", sign(t, "RS256", "rsa-1", rsaKey, with("exp", now-30))},
+		{ErrTokenExpired, "expired", sign(t, "RS256", "rsa-1", rsaKey, with("exp", now-120))},
+		{ErrTokenNotValid, "not before", sign(t, "RS256", "rsa-1", rsaKey, with("nbf", now+120))},
+		{ErrTokenIssuer, "issuer", sign(t, "RS256", "rsa-1", rsaKey, with("iss", "https://evil.com"))},
+		{ErrTokenAudience, "audience", sign(t, "RS256", "rsa-1", rsaKey, with("aud", "other"))},
+		{ErrTokenSignature, "wrong key", sign(t, "ES256", "rsa-1", ecKey, valid)},
+		{ErrTokenSignature, "hs with the public key", sign(t, "HS256", "rsa-1", rsaKey.N.Bytes(), valid)},
+		{ErrTokenMalformed, "malformed", "a.b"},
+	} {
+		claims, err := v.Verify(ctx, tc.token)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: want %v, got %v", tc.name, tc.err, err)
+			continue
+		}
+
+		if err == nil && (claims.Subject != "u-1" || claims.Operator != "alice") {
+			t.Errorf("%s: unexpected claims %+v", tc.name, claims)
+		}
+	}
+
+	// keys are rotated by the provider, the new key id triggers a reload
+	rotated, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writeJWKS(t, file, rsaJWK("rsa-2", rotated))
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(file, future, future)
+
+	if _, err = v.Verify(ctx, sign(t, "RS256", "rsa-2", rotated, valid)); err != nil {
+		t.Errorf("rotated key: %v", err)
+	}
+	if _, err = v.Verify(ctx, sign(t, "RS256", "rsa-1", rsaKey, valid)); !errors.Is(err, ErrTokenSignature) {
+		t.Errorf("removed key: want %v, got %v", ErrTokenSignature, err)
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	v, err := NewVerifier(Config{Algorithms: []string{"HS256"}, HMACSecret: "secret"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	claims := map[string]interface{}{"sub": "bob"}
+	if _, err = v.Verify(context.Background(), sign(t, "HS256", "", []byte("secret"), claims)); !errors.Is(err, ErrTokenNoExpiry) {
+		t.Errorf("without exp: want %v, got %v", ErrTokenNoExpiry, err)
+	}
+
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	if _, err = v.Verify(context.Background(), sign(t, "HS256", "", []byte("secret"), claims)); err != nil {
+		t.Error(err.Error())
+	}
+	if _, err = v.Verify(context.Background(), sign(t, "HS256", "", []byte("guess"), claims)); !errors.Is(err, ErrTokenSignature) {
+		t.Errorf("want %v, got %v", ErrTokenSignature, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := Config{Algorithms: []string{"RS256", "none"}, JWKSURL: "ftp://idp"}
+	if errs := c.Validate(); len(errs) != 2 {
+		t.Errorf("want 2 problems, got %v", errs)
+	}
+}
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -20,6 +21,47 @@ type (
 	}
 )
 
+// Validate returns all problems of the config
+func (c *Config) Validate() (errs []error) {
+	if strings.TrimSpace(c.Addr) == "" {
+		errs = append(errs, fmt.Errorf("addr is required"))
+	}
+
+	if c.KafkaVersion != "" {
+		version, err := sarama.ParseKafkaVersion(c.KafkaVersion)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid kafka_version %q: %s", c.KafkaVersion, err.Error()))
+		} else if !isSupportedVersion(version) {
+			errs = append(errs, fmt.Errorf("kafka_version %q is not supported, the minimum is %s and the maximum is %s",
+				c.KafkaVersion, sarama.MinVersion, sarama.MaxVersion))
+		}
+	}
+
+	if c.EnableLog {
+		switch c.LogLevel {
+		case "", LogDebug, "info":
+		default:
+			errs = append(errs, fmt.Errorf("unsupported log_level %q, only support debug/info", c.LogLevel))
+		}
+	}
+
+	if c.QueueLength < 0 {
+		errs = append(errs, fmt.Errorf("queue_length must not be negative"))
+	}
+
+	return errs
+}
+
+func isSupportedVersion(version sarama.KafkaVersion) bool {
+	for _, v := range sarama.SupportedVersions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
 // BuildKafka 创建Kafka客户端实例
 func (c *Config) BuildKafka(ctx context.Context) (*CliCfg, error) {
 	logger.Debug("build kafka client")
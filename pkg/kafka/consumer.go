@@ -7,9 +7,13 @@
 package kafka
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/Shopify/sarama"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
@@ -39,17 +43,20 @@ func (cc *ConsumerClient) RunConsumer(group string, topic []string, groupHandler
 	cc.group[group] = consumerGroupClient
 
 	go func() {
-		for {
-			err := <-consumerGroupClient.Errors()
-			if err != nil {
-				logger.Error(err)
-			}
+		// the chan is closed after the consumer group is closed
+		for err := range consumerGroupClient.Errors() {
+			logger.Error(err)
 		}
 	}()
 
 	go func() {
 		for {
 			err := consumerGroupClient.Consume(cc.kafkaOptions.ctx, topic, groupHandler)
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) || cc.kafkaOptions.ctx.Err() != nil {
+				logger.Infof("consumer of group %s stopped", group)
+				return
+			}
+
 			if err != nil {
 				logger.Errorf("error from consumer group: %s", err.Error())
 			}
@@ -83,10 +90,24 @@ func (ConsumerGroup) Setup(sarama.ConsumerGroupSession) error   { return nil }
 func (ConsumerGroup) Cleanup(sarama.ConsumerGroupSession) error { return nil }
 func (h ConsumerGroup) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for msg := range claim.Messages() {
-		logger.Debugf("find message of topic: %q, partition: %d, offset: %d", msg.Topic, msg.Partition, msg.Offset)
+		logger.FromContext(MessageContext(msg)).Debug("find message")
 		h.handler(msg)
 		sess.MarkMessage(msg, "")
 	}
 
 	return nil
 }
+
+// MessageContext returns a context with the request ID in the X-Request-ID header of msg,
+// and the topic, partition and offset of msg as log fields, for logger.FromContext and logger.*WithTrace
+func MessageContext(msg *sarama.ConsumerMessage) context.Context {
+	ctx := context.Background()
+	for _, h := range msg.Headers {
+		if h != nil && strings.EqualFold(string(h.Key), gadget.RequestIDHeader) {
+			ctx = gadget.WithRequestID(ctx, string(h.Value))
+			break
+		}
+	}
+
+	return logger.WithContext(ctx, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+}
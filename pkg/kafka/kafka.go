@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
+// pingTimeout bounds the network calls of Ping, so that they do not outlive the probes
+const pingTimeout = 3 * time.Second
+
 var (
 	// 仅用于单例模式
 	_defaultKafka *CliCfg
@@ -26,6 +31,11 @@ type CliCfg struct {
 	kafkaCfg *sarama.Config // 生产者配置
 	config   *Config        // kafka配置
 	addr     []string       // kafka集群地址
+	closers  []func()       // 关闭由该客户端创建的生产者和消费者
+	lock     sync.Mutex
+
+	pingClient sarama.Client // 供 Ping 复用的客户端，避免每次探测都重新连接所有 broker
+	pingLock   sync.Mutex
 }
 
 // 仅用于单例模式
@@ -45,6 +55,67 @@ func (k *CliCfg) checkCli() error {
 	return nil
 }
 
+// Ping fetches the metadata of the cluster to make sure brokers are reachable
+func (k *CliCfg) Ping(ctx context.Context) error {
+	if err := k.checkCli(); err != nil {
+		return err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		client, err := k.getPingClient()
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		errChan <- client.RefreshMetadata()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// getPingClient returns the long-lived client of Ping, whose network calls are bounded by pingTimeout
+func (k *CliCfg) getPingClient() (sarama.Client, error) {
+	k.pingLock.Lock()
+	defer k.pingLock.Unlock()
+
+	if k.pingClient != nil && !k.pingClient.Closed() {
+		return k.pingClient, nil
+	}
+
+	cfg := *k.kafkaCfg
+	for _, timeout := range []*time.Duration{&cfg.Net.DialTimeout, &cfg.Net.ReadTimeout, &cfg.Net.WriteTimeout} {
+		if *timeout <= 0 || *timeout > pingTimeout {
+			*timeout = pingTimeout
+		}
+	}
+
+	client, err := sarama.NewClient(k.addr, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	k.pingClient = client
+	k.addCloser(k.closePingClient)
+
+	return client, nil
+}
+
+func (k *CliCfg) closePingClient() {
+	k.pingLock.Lock()
+	defer k.pingLock.Unlock()
+
+	if k.pingClient != nil && !k.pingClient.Closed() {
+		_ = k.pingClient.Close()
+	}
+}
+
 func (k *CliCfg) NewAsyncProducerClient() (AsyncProducer, error) {
 	if err := k.checkCli(); err != nil {
 		return nil, err
@@ -74,6 +145,8 @@ func (k *CliCfg) NewAsyncProducerClient() (AsyncProducer, error) {
 	}
 
 	cli.asyncProducer = producer
+	k.addCloser(cli.CloseProducer)
+
 	return cli, nil
 }
 
@@ -82,7 +155,30 @@ func (k *CliCfg) NewSyncProducerClient() (sarama.SyncProducer, error) {
 		return nil, err
 	}
 
-	return sarama.NewSyncProducer(k.addr, k.kafkaCfg)
+	producer, err := sarama.NewSyncProducer(k.addr, k.kafkaCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &syncProducer{SyncProducer: producer}
+	k.addCloser(func() { _ = p.Close() })
+
+	return p, nil
+}
+
+// syncProducer makes Close safe to be called by both the caller and CliCfg.Close
+type syncProducer struct {
+	sarama.SyncProducer
+	closeErr  error
+	closeOnce sync.Once
+}
+
+func (p *syncProducer) Close() error {
+	p.closeOnce.Do(func() {
+		p.closeErr = p.SyncProducer.Close()
+	})
+
+	return p.closeErr
 }
 
 func (k *CliCfg) NewConsumer() (*ConsumerClient, error) {
@@ -90,8 +186,38 @@ func (k *CliCfg) NewConsumer() (*ConsumerClient, error) {
 		return nil, err
 	}
 
-	return &ConsumerClient{
+	consumer := &ConsumerClient{
 		kafkaOptions: k,
 		group:        make(map[string]sarama.ConsumerGroup),
-	}, nil
+	}
+	k.addCloser(consumer.Close)
+
+	return consumer, nil
+}
+
+func (k *CliCfg) addCloser(fn func()) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.closers = append(k.closers, fn)
+}
+
+// Close closes all producers and consumers created by the client, in reverse order of creation
+func (k *CliCfg) Close() {
+	if k == nil {
+		return
+	}
+
+	k.lock.Lock()
+	closers := k.closers
+	k.closers = nil
+	k.lock.Unlock()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i]()
+	}
+
+	if _defaultKafka == k {
+		_defaultKafka = nil
+	}
 }
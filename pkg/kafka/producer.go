@@ -9,24 +9,28 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
 	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
 type sendMessage struct {
-	topic string
-	key   string
-	value []byte
+	topic     string
+	key       string
+	requestID string
+	value     []byte
 }
 
 type AsyncProducer interface {
-	RunAsyncProducer()                                        // 运行异步生产者线程
-	Produce(topic string, value []byte, keys ...string) error // 生产消息
-	ProducerErrors() <-chan *sarama.ProducerError             // 返回生产者发送消息失败的chan
-	CloseProducer()                                           // 关闭线程
-	IsRunning() bool                                          // 运行状态
+	RunAsyncProducer()                                                                        // 运行异步生产者线程
+	Produce(topic string, value []byte, keys ...string) error                                 // 生产消息
+	ProduceWithContext(ctx context.Context, topic string, value []byte, keys ...string) error // 生产消息，并将 ctx 中的 request ID 写入消息头
+	ProducerErrors() <-chan *sarama.ProducerError                                             // 返回生产者发送消息失败的chan
+	CloseProducer()                                                                           // 关闭线程
+	IsRunning() bool                                                                          // 运行状态
 }
 
 type AsyncProducerClient struct {
@@ -35,6 +39,7 @@ type AsyncProducerClient struct {
 	asyncError    chan *sarama.ProducerError // 错误消息队列
 	messageChan   chan *sendMessage          // 发送生产消息的队列
 	errLength     int                        // 错误消息最大长度
+	closeOnce     sync.Once                  // 避免重复关闭生产者
 	isRunning     bool                       // 生产者线程是否运行
 }
 
@@ -85,6 +90,9 @@ func (p *AsyncProducerClient) RunAsyncProducer() {
 				if m.key != "" {
 					msg.Key = sarama.StringEncoder(m.key)
 				}
+				if m.requestID != "" {
+					msg.Headers = []sarama.RecordHeader{{Key: []byte(gadget.RequestIDHeader), Value: []byte(m.requestID)}}
+				}
 
 				producer.Input() <- msg
 				logger.Debugf("sent to kafka, topic: %s, messages_len: %d", msg.Topic, msg.Value.Length())
@@ -95,13 +103,19 @@ func (p *AsyncProducerClient) RunAsyncProducer() {
 
 // Produce 发送消息到队列。仅当需要保证消息顺序时，才使用参数 keys，并且只允许传一个 key
 func (p *AsyncProducerClient) Produce(topic string, value []byte, keys ...string) error {
+	return p.ProduceWithContext(context.Background(), topic, value, keys...)
+}
+
+// ProduceWithContext 同 Produce，ctx 中的 request ID 写入消息头 X-Request-ID，需要 Kafka 0.11 及以上版本
+func (p *AsyncProducerClient) ProduceWithContext(ctx context.Context, topic string, value []byte, keys ...string) error {
 	if !p.isRunning {
 		p.RunAsyncProducer()
 	}
 
 	msg := &sendMessage{
-		topic: topic,
-		value: value,
+		topic:     topic,
+		value:     value,
+		requestID: gadget.RequestID(ctx),
 	}
 
 	switch len(keys) {
@@ -121,8 +135,10 @@ func (p *AsyncProducerClient) ProducerErrors() <-chan *sarama.ProducerError {
 }
 
 func (p *AsyncProducerClient) CloseProducer() {
-	p.isRunning = false
-	p.asyncProducer.AsyncClose()
+	p.closeOnce.Do(func() {
+		p.isRunning = false
+		p.asyncProducer.AsyncClose()
+	})
 }
 
 func (p *AsyncProducerClient) IsRunning() bool {
@@ -20,16 +20,35 @@ var (
 type Config struct {
 	Level       LogLevel        `yaml:"level" env:"LogLevel" env-default:"info" env-description:"log level" json:"level,omitempty"`
 	Encoding    ZapConfEncoding `yaml:"encoding" env:"LogEncoding" env-default:"console" env-description:"log encoding" json:"encoding,omitempty"`
-	LogPath     string          `yaml:"log_path" env:"LogPath" env-description:"which path the log file should be" json:"log_path,omitempty"`
-	LogName     string          `yaml:"log_name" env:"LogFileName" env-description:"which file name the log file should be" json:"log_name,omitempty"`
-	MaxSize     int             `yaml:"max_size" env:"LogMaxSize" env-description:"max size of rotating" json:"max_size,omitempty"`
-	MaxAge      int             `yaml:"max_age" env:"LogMaxAge" env-description:"max age of rotating" json:"max_age,omitempty"`
-	LocalTime   bool            `yaml:"localtime" json:"local_time,omitempty"`
-	Compress    bool            `yaml:"compress" env:"LogCompress" env-description:"compress old log files or not" json:"compress,omitempty"`
+	LogPath     string          `yaml:"log_path" env:"LogPath" env-description:"which path the log file should be" json:"log_path,omitempty" reload:"restart"`
+	LogName     string          `yaml:"log_name" env:"LogFileName" env-description:"which file name the log file should be" json:"log_name,omitempty" reload:"restart"`
+	MaxSize     int             `yaml:"max_size" env:"LogMaxSize" env-description:"max size of rotating" json:"max_size,omitempty" reload:"restart"`
+	MaxAge      int             `yaml:"max_age" env:"LogMaxAge" env-description:"max age of rotating" json:"max_age,omitempty" reload:"restart"`
+	LocalTime   bool            `yaml:"localtime" json:"local_time,omitempty" reload:"restart"`
+	Compress    bool            `yaml:"compress" env:"LogCompress" env-description:"compress old log files or not" json:"compress,omitempty" reload:"restart"`
 	Development bool            `yaml:"development" json:"development,omitempty"`
 	EnableTrace bool            `yaml:"enable_trace" json:"enable_trace,omitempty"`
 }
 
+// Validate returns all problems of the config
+func (c Config) Validate() (errs []error) {
+	switch c.Level {
+	case "", "debug", "info", "warn", "error", "dpanic", "panic", "fatal":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported level %q", c.Level))
+	}
+
+	if c.Encoding != "" && !c.Encoding.IsValid() {
+		errs = append(errs, fmt.Errorf("unsupported encoding %q, only support json/console", c.Encoding))
+	}
+
+	if c.MaxSize < 0 || c.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("max_size and max_age must not be negative"))
+	}
+
+	return errs
+}
+
 type Options struct {
 	zapConfig zap.Config
 	Config
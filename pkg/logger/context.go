@@ -0,0 +1,147 @@
+/*
+@Date: 2026/10/19 20:20
+@Author: yvanz
+@File : context
+*/
+
+package logger
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
+	"go.uber.org/zap"
+)
+
+// FieldsCtxKey is the key of the log fields in gin.Context, see WithContext
+const FieldsCtxKey = "log_fields"
+
+type fieldsKey struct{}
+
+// WithContext returns a copy of ctx carrying fields, which are logged by FromContext and the *WithTrace functions.
+// fields are zap.Field or loosely-typed key-value pairs as SugaredLogger.With, a field replaces the one with the same key.
+// If ctx is a *gin.Context the fields are set on it and its request instead, and ctx itself is returned,
+// so that the handlers after the middleware calling it see the fields.
+func WithContext(ctx context.Context, fields ...interface{}) context.Context {
+	merged := mergeFields(storedFields(ctx), fields)
+
+	if c, ok := ctx.(*gin.Context); ok {
+		c.Set(FieldsCtxKey, merged)
+		if c.Request != nil {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), fieldsKey{}, merged))
+		}
+
+		return c
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FromContext returns the default logger with the request ID, the trace and the fields set by WithContext in ctx
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	// the default logger skips the package-level wrappers, which are not in the call stack here
+	return Default().Desugar().WithOptions(zap.AddCallerSkip(-1)).With(Fields(ctx)...).Sugar()
+}
+
+// ctxLogger is FromContext for the package-level wrappers
+func ctxLogger(ctx context.Context) *zap.SugaredLogger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return Default()
+	}
+
+	return Default().Desugar().With(fields...).Sugar()
+}
+
+func storedFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	if fields, ok := ctx.Value(fieldsKey{}).([]zap.Field); ok {
+		return fields
+	}
+
+	// gin.Context looks up the keys set by Set
+	fields, _ := ctx.Value(FieldsCtxKey).([]zap.Field)
+	return fields
+}
+
+func mergeFields(old []zap.Field, fields []interface{}) []zap.Field {
+	merged := append([]zap.Field{}, old...)
+	add := func(f zap.Field) {
+		for i := range merged {
+			if merged[i].Key == f.Key {
+				merged[i] = f
+				return
+			}
+		}
+		merged = append(merged, f)
+	}
+
+	for i := 0; i < len(fields); i++ {
+		switch f := fields[i].(type) {
+		case zap.Field:
+			add(f)
+		case string:
+			if i+1 < len(fields) {
+				add(zap.Any(f, fields[i+1]))
+				i++
+			}
+		}
+	}
+
+	return merged
+}
+
+// Fields returns the request ID, the trace and the fields set by WithContext in ctx, for the loggers other than the default one
+func Fields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []zap.Field
+	if id := gadget.RequestID(ctx); id != "" {
+		fields = append(fields, zap.String(gadget.RequestIDCtxKey, id))
+	}
+
+	if jaegerCtx, ok := jaegerContext(ctx); ok {
+		fields = append(fields,
+			zap.String("trace_id", jaegerCtx.TraceID().String()),
+			zap.String("span_id", jaegerCtx.SpanID().String()),
+		)
+	}
+
+	return append(fields, storedFields(ctx)...)
+}
+
+// TraceID returns the jaeger trace ID of ctx, or empty if it is not traced
+func TraceID(ctx context.Context) string {
+	if jaegerCtx, ok := jaegerContext(ctx); ok {
+		return jaegerCtx.TraceID().String()
+	}
+
+	return ""
+}
+
+func jaegerContext(ctx context.Context) (jaeger.SpanContext, bool) {
+	spanCtx, err := gadget.ExtractTraceSpan(ctx)
+	if err != nil {
+		return jaeger.SpanContext{}, false
+	}
+
+	span := opentracing.SpanFromContext(spanCtx)
+	if span == nil {
+		return jaeger.SpanContext{}, false
+	}
+
+	jaegerCtx, ok := span.Context().(jaeger.SpanContext)
+	return jaegerCtx, ok
+}
@@ -0,0 +1,81 @@
+/*
+@Date: 2026/10/19 20:30
+@Author: yvanz
+@File : context_test
+*/
+
+package logger
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func observe(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	old := Default()
+	DefaultLog.logger.Store(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)).Sugar())
+	t.Cleanup(func() { DefaultLog.logger.Store(old) })
+
+	return logs
+}
+
+func TestFromContext(t *testing.T) {
+	logs := observe(t)
+
+	ctx := gadget.WithRequestID(context.Background(), "req-1")
+	ctx = WithContext(ctx, "route", "GET /a", zap.String("operator", "alice"))
+	ctx = WithContext(ctx, "route", "GET /b", "dangling")
+
+	FromContext(ctx).Info("direct")
+	InfofWithTrace(ctx, "%s", "wrapped")
+	FromContext(nil).Info("nil") //nolint:staticcheck
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+
+	for _, e := range entries[:2] {
+		fields := e.ContextMap()
+		if fields["request_id"] != "req-1" || fields["route"] != "GET /b" || fields["operator"] != "alice" || len(fields) != 3 {
+			t.Errorf("%s: unexpected fields %v", e.Message, fields)
+		}
+
+		if file := filepath.Base(e.Caller.File); file != "context_test.go" {
+			t.Errorf("%s: caller is %s", e.Message, file)
+		}
+	}
+
+	if fields := entries[2].ContextMap(); len(fields) != 0 {
+		t.Errorf("unexpected fields %v", fields)
+	}
+}
+
+func TestWithGinContext(t *testing.T) {
+	logs := observe(t)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	if ctx := WithContext(c, "operator", "bob"); ctx != c {
+		t.Fatal("the gin context is not returned")
+	}
+
+	ErrorWithTrace(c, "gin")
+	ErrorWithTrace(c.Request.Context(), "request")
+
+	for _, e := range logs.AllUntimed() {
+		if fields := e.ContextMap(); fields["operator"] != "bob" {
+			t.Errorf("%s: unexpected fields %v", e.Message, fields)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+@Date: 2026/10/19 21:10
+@Author: yvanz
+@File : file
+*/
+
+package logger
+
+import (
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileConfig is a log file apart from the application logs, such as the request details, with its own rotation
+type FileConfig struct {
+	File       string `yaml:"file" json:"file,omitempty"`
+	MaxSize    int    `yaml:"max_size" json:"max_size,omitempty"`
+	MaxAge     int    `yaml:"max_age" json:"max_age,omitempty"`
+	MaxBackups int    `yaml:"max_backups" json:"max_backups,omitempty"`
+	LocalTime  bool   `yaml:"localtime" json:"local_time,omitempty"`
+	Compress   bool   `yaml:"compress" json:"compress,omitempty"`
+}
+
+// Validate returns all problems of the config
+func (c FileConfig) Validate() (errs []error) {
+	if c.MaxSize < 0 || c.MaxAge < 0 || c.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("max_size, max_age and max_backups must not be negative"))
+	}
+
+	return errs
+}
+
+// NewFileWriter returns the file of c, which is rotated as the application logs by default
+func NewFileWriter(c FileConfig) *lumberjack.Logger {
+	rotate := initLumberjackConf(&Options{Config: Config{
+		MaxSize: c.MaxSize, MaxAge: c.MaxAge, LocalTime: c.LocalTime, Compress: c.Compress,
+	}})
+	rotate.Filename = c.File
+	rotate.MaxBackups = c.MaxBackups
+
+	return rotate
+}
+
+// NewFileLogger returns a JSON logger writing to the file of c by NewFileWriter.
+// Close the closer when the logger is replaced.
+func NewFileLogger(c FileConfig) (*zap.Logger, io.Closer) {
+	rotate := NewFileWriter(c)
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(rotate), zapcore.DebugLevel)
+	return zap.New(core), rotate
+}
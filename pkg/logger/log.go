@@ -24,20 +24,22 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"sync"
+	"sync/atomic"
 
-	"github.com/opentracing/opentracing-go"
-	"github.com/uber/jaeger-client-go"
-	"github.com/yvanz/gin-tmpl/pkg/gadget"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 var (
 	DefaultLog *DemoLog
+	// serializes ConfigureLogger, which changes the config of DefaultLog
+	configLock sync.Mutex
 )
 
 type DemoLog struct {
-	*zap.SugaredLogger
+	// *zap.SugaredLogger, replaced as a whole when the config is reloaded while it is being used
+	logger      atomic.Value
 	config      *zap.Config
 	logDir      string
 	logBaseName string
@@ -58,15 +60,27 @@ func init() {
 }
 
 func ConfigureLogger(logOptions *Options) *DemoLog {
-	if logOptions.CompareOptions() != defaultConfig.CompareOptions() {
-		DefaultLog.config.Level = logOptions.Level.Level()
+	configLock.Lock()
+	defer configLock.Unlock()
+
+	// level, development and encoding could be changed by calling it again, the level is kept
+	// as the same atomic level so that loggers derived from the old one follow it as well,
+	// the logger is rebuilt only if the others change
+	DefaultLog.config.Level.SetLevel(logOptions.Level.parse())
+	rebuild := DefaultLog.logger.Load() == nil
+	if DefaultLog.config.Development != logOptions.Development {
 		DefaultLog.config.Development = logOptions.Development
+		rebuild = true
+	}
+	if logOptions.Encoding.IsValid() && DefaultLog.config.Encoding != logOptions.Encoding.String() {
+		DefaultLog.config.Encoding = logOptions.Encoding.String()
+		rebuild = true
+	}
 
-		if logOptions.EnableTrace {
-			defaultConfig.zapConfig.DisableStacktrace = false
-		}
-		if logOptions.Encoding != "" && logOptions.Encoding.IsValid() {
-			DefaultLog.config.Encoding = logOptions.Encoding.String()
+	if logOptions.CompareOptions() != defaultConfig.CompareOptions() {
+		if logOptions.EnableTrace && DefaultLog.config.DisableStacktrace {
+			DefaultLog.config.DisableStacktrace = false
+			rebuild = true
 		}
 
 		if logOptions.LogPath != "" {
@@ -91,8 +105,11 @@ func ConfigureLogger(logOptions *Options) *DemoLog {
 				rotatePath = fmt.Sprintf("rotate:/%s", fullPath)
 			}
 
-			DefaultLog.config.OutputPaths = append(DefaultLog.config.OutputPaths, rotatePath)
-			DefaultLog.config.ErrorOutputPaths = append(DefaultLog.config.ErrorOutputPaths, rotatePath)
+			if !containsPath(DefaultLog.config.OutputPaths, rotatePath) {
+				DefaultLog.config.OutputPaths = append(DefaultLog.config.OutputPaths, rotatePath)
+				DefaultLog.config.ErrorOutputPaths = append(DefaultLog.config.ErrorOutputPaths, rotatePath)
+				rebuild = true
+			}
 
 			logRotate := logRotationConfig{initLumberjackConf(logOptions)}
 
@@ -103,19 +120,34 @@ func ConfigureLogger(logOptions *Options) *DemoLog {
 		}
 	}
 
+	if !rebuild {
+		return DefaultLog
+	}
+
 	// Skip this wrapper in a call stack.
 	logger, err := DefaultLog.config.Build(zap.AddCallerSkip(1))
 	if err != nil {
 		panic(err)
 	}
 
-	DefaultLog.SugaredLogger = logger.Sugar()
+	// the loggers in use keep working, new calls of Default get the new one
+	DefaultLog.logger.Store(logger.Sugar())
 
 	return DefaultLog
 }
 
+func containsPath(paths []string, p string) bool {
+	for _, v := range paths {
+		if v == p {
+			return true
+		}
+	}
+
+	return false
+}
+
 func Default() *zap.SugaredLogger {
-	return DefaultLog.SugaredLogger
+	return DefaultLog.logger.Load().(*zap.SugaredLogger)
 }
 
 // Debug uses fmt.Sprint to construct and log a message.
@@ -129,14 +161,7 @@ func Info(args ...interface{}) {
 }
 
 func InfoWithTrace(ctx context.Context, args ...interface{}) {
-	spanData := extractSpan(ctx)
-	if spanData == nil {
-		Default().Info(args...)
-		return
-	}
-
-	l := With(spanData...)
-	l.Info(args...)
+	ctxLogger(ctx).Info(args...)
 }
 
 // Warn uses fmt.Sprint to construct and log a message.
@@ -145,14 +170,7 @@ func Warn(args ...interface{}) {
 }
 
 func WarnWithTrace(ctx context.Context, args ...interface{}) {
-	spanData := extractSpan(ctx)
-	if spanData == nil {
-		Default().Warn(args...)
-		return
-	}
-
-	l := With(spanData...)
-	l.Warn(args...)
+	ctxLogger(ctx).Warn(args...)
 }
 
 // Error uses fmt.Sprint to construct and log a message.
@@ -161,14 +179,7 @@ func Error(args ...interface{}) {
 }
 
 func ErrorWithTrace(ctx context.Context, args ...interface{}) {
-	spanData := extractSpan(ctx)
-	if spanData == nil {
-		Default().Error(args...)
-		return
-	}
-
-	l := With(spanData...)
-	l.Error(args...)
+	ctxLogger(ctx).Error(args...)
 }
 
 // Panic uses fmt.Sprint to construct and log a message, then panics.
@@ -196,20 +207,17 @@ func Debugf(template string, args ...interface{}) {
 	Default().Debugf(template, args...)
 }
 
+func DebugfWithTrace(ctx context.Context, template string, args ...interface{}) {
+	ctxLogger(ctx).Debugf(template, args...)
+}
+
 // Infof uses fmt.Sprintf to log a templated message.
 func Infof(template string, args ...interface{}) {
 	Default().Infof(template, args...)
 }
 
 func InfofWithTrace(ctx context.Context, template string, args ...interface{}) {
-	spanData := extractSpan(ctx)
-	if spanData == nil {
-		Default().Infof(template, args...)
-		return
-	}
-
-	l := With(spanData...)
-	l.Infof(template, args...)
+	ctxLogger(ctx).Infof(template, args...)
 }
 
 // Warnf uses fmt.Sprintf to log a templated message.
@@ -218,14 +226,7 @@ func Warnf(template string, args ...interface{}) {
 }
 
 func WarnfWithTrace(ctx context.Context, template string, args ...interface{}) {
-	spanData := extractSpan(ctx)
-	if spanData == nil {
-		Default().Warnf(template, args...)
-		return
-	}
-
-	l := With(spanData...)
-	l.Warnf(template, args...)
+	ctxLogger(ctx).Warnf(template, args...)
 }
 
 // Errorf uses fmt.Sprintf to log a templated message.
@@ -234,14 +235,7 @@ func Errorf(template string, args ...interface{}) {
 }
 
 func ErrorfWithTrace(ctx context.Context, template string, args ...interface{}) {
-	spanData := extractSpan(ctx)
-	if spanData == nil {
-		Default().Errorf(template, args...)
-		return
-	}
-
-	l := With(spanData...)
-	l.Errorf(template, args...)
+	ctxLogger(ctx).Errorf(template, args...)
 }
 
 // Panicf uses fmt.Sprintf to log a templated message, then panics.
@@ -315,27 +309,6 @@ func With(args ...interface{}) *zap.SugaredLogger {
 	return Default().With(args...)
 }
 
-func extractSpan(ctx context.Context) []interface{} {
-	spanCtx, err := gadget.ExtractTraceSpan(ctx)
-	if err != nil {
-		return nil
-	}
-
-	span := opentracing.SpanFromContext(spanCtx)
-	if span != nil {
-		jaegerCtx, ok := span.Context().(jaeger.SpanContext)
-		if ok {
-			res := []interface{}{
-				"trace_id", jaegerCtx.TraceID().String(),
-				"span_id", jaegerCtx.SpanID().String(),
-			}
-			return res
-		}
-	}
-
-	return nil
-}
-
 type Logger interface {
 	// Error logs a message at error priority
 	Error(msg string)
@@ -351,3 +324,19 @@ func (d *DemoLog) Error(msg string) {
 func (d *DemoLog) Infof(template string, args ...interface{}) {
 	Default().Infof(template, args...)
 }
+
+func (d *DemoLog) Info(args ...interface{}) {
+	Default().Info(args...)
+}
+
+func (d *DemoLog) Warnf(template string, args ...interface{}) {
+	Default().Warnf(template, args...)
+}
+
+func (d *DemoLog) Errorf(template string, args ...interface{}) {
+	Default().Errorf(template, args...)
+}
+
+func (d *DemoLog) Sync() error {
+	return Default().Sync()
+}
@@ -0,0 +1,57 @@
+/*
+@Date: 2026/10/19 22:40
+@Author: yvanz
+@File : log_test
+*/
+
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConfigureLoggerWhileLogging(t *testing.T) {
+	t.Cleanup(func() {
+		ConfigureLogger(&Options{Config: Config{Level: "info", Encoding: ZapEncodeConsole}})
+	})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					// below the level, the logger is loaded but nothing is written
+					Debugf("reloading %d", n)
+				}
+			}
+		}(i)
+	}
+
+	for i := 0; i < 100; i++ {
+		encoding := ZapEncodeConsole
+		if i%2 == 0 {
+			encoding = ZapEncodeJSON
+		}
+		ConfigureLogger(&Options{Config: Config{Level: "info", Encoding: encoding}})
+	}
+	close(stop)
+	wg.Wait()
+
+	before := Default()
+	ConfigureLogger(&Options{Config: Config{Level: "warn", Encoding: ZapEncodeConsole}})
+	if Default() != before {
+		t.Error("the logger is rebuilt without any change but the level")
+	}
+	if Default().Desugar().Core().Enabled(zapcore.InfoLevel) {
+		t.Error("the level is not changed")
+	}
+}
@@ -0,0 +1,335 @@
+/*
+@Date: 2026/10/19 22:00
+@Author: yvanz
+@File : accesslog
+*/
+
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	AccessLogJSON     = "json"
+	AccessLogCombined = "combined"
+	AccessLogTemplate = "template"
+)
+
+var (
+	_accessLog atomic.Value
+	// serializes SetAccessLog, which closes the replaced sink
+	accessLogLock sync.Mutex
+
+	accessLogField = regexp.MustCompile(`\{([a-z_]+)\}`)
+)
+
+func init() {
+	p, _ := newAccessLogPolicy(AccessLogConfig{Format: AccessLogCombined})
+	p.logger = newAccessLogger(p.conf.Format, zapcore.Lock(os.Stdout))
+	_accessLog.Store(p)
+}
+
+// AccessLogConfig configures the access logs of AccessLog.
+// The template has the fields in braces such as {method} {path} {status} {latency_ms}, see accessEntry.value.
+// SampleRates are the ratios of the requests logged by route, such as /ping: 0.01, the error responses are always logged.
+type AccessLogConfig struct {
+	SampleRates map[string]float64 `yaml:"sample_rates" json:"sample_rates,omitempty"`
+	Format      string             `yaml:"format" env:"AccessLogFormat" env-default:"combined" env-description:"format of the access logs: json/combined/template" json:"format,omitempty"`
+	Template    string             `yaml:"template" env:"AccessLogTemplate" env-description:"template of the access logs in the template format" json:"template,omitempty"`
+	Sink        logger.FileConfig  `yaml:"sink" json:"sink,omitempty" reload:"restart"`
+}
+
+// Validate returns all problems of the config
+func (c AccessLogConfig) Validate() (errs []error) {
+	switch c.Format {
+	case "", AccessLogJSON, AccessLogCombined:
+	case AccessLogTemplate:
+		if c.Template == "" {
+			errs = append(errs, fmt.Errorf("template is required by the template format"))
+		}
+		for _, m := range accessLogField.FindAllStringSubmatch(c.Template, -1) {
+			if !accessLogFields[m[1]] {
+				errs = append(errs, fmt.Errorf("unknown field {%s} in template", m[1]))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported format %q, only support json/combined/template", c.Format))
+	}
+
+	for route, rate := range c.SampleRates {
+		if rate < 0 || rate > 1 {
+			errs = append(errs, fmt.Errorf("sample rate of %s must be between 0 and 1", route))
+		}
+	}
+
+	return append(errs, c.Sink.Validate()...)
+}
+
+type accessLogPolicy struct {
+	logger *zap.Logger
+	file   *lumberjack.Logger
+	conf   AccessLogConfig
+}
+
+func newAccessLogPolicy(c AccessLogConfig) (*accessLogPolicy, error) {
+	if c.Format == "" {
+		c.Format = AccessLogCombined
+	}
+	if errs := c.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return &accessLogPolicy{conf: c}, nil
+}
+
+// newAccessLogger writes the entries in JSON, or the lines as they are in the other formats
+func newAccessLogger(format string, w zapcore.WriteSyncer) *zap.Logger {
+	var encoder zapcore.Encoder
+	if format == AccessLogJSON {
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderConfig.LevelKey = ""
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(zapcore.EncoderConfig{MessageKey: "msg", LineEnding: zapcore.DefaultLineEnding})
+	}
+
+	return zap.New(zapcore.NewCore(encoder, w, zapcore.DebugLevel))
+}
+
+// SetAccessLog replaces the format and the sample rates of the access logs, which are written to the sink,
+// or stdout if the file of the sink is not set.
+func SetAccessLog(c AccessLogConfig) error {
+	accessLogLock.Lock()
+	defer accessLogLock.Unlock()
+
+	p, err := newAccessLogPolicy(c)
+	if err != nil {
+		return err
+	}
+
+	current := getAccessLog()
+	p.logger, p.file = current.logger, current.file
+	if p.conf.Sink != current.conf.Sink {
+		p.file = nil
+		if p.conf.Sink.File != "" {
+			p.file = logger.NewFileWriter(p.conf.Sink)
+		}
+	}
+
+	if p.conf.Sink != current.conf.Sink || p.conf.Format != current.conf.Format {
+		w := zapcore.Lock(os.Stdout)
+		if p.file != nil {
+			w = zapcore.AddSync(p.file)
+		}
+		p.logger = newAccessLogger(p.conf.Format, w)
+	}
+
+	_accessLog.Store(p)
+	if current.file != nil && current.file != p.file {
+		_ = current.file.Close()
+	}
+
+	return nil
+}
+
+func getAccessLog() *accessLogPolicy {
+	return _accessLog.Load().(*accessLogPolicy)
+}
+
+// sampled reports whether the request of the route is logged
+func (p *accessLogPolicy) sampled(route string, status int) bool {
+	rate, ok := p.conf.SampleRates[route]
+	if !ok || status >= 400 {
+		return true
+	}
+
+	return rand.Float64() < rate //nolint:gosec
+}
+
+var accessLogFields = map[string]bool{
+	"time": true, "client": true, "method": true, "path": true, "route": true, "proto": true,
+	"status": true, "latency_ms": true, "bytes_in": true, "bytes_out": true, "user_agent": true,
+	"referer": true, "request_id": true, "trace_id": true, "user": true, "error": true,
+}
+
+type accessEntry struct {
+	time      time.Time
+	client    string
+	method    string
+	path      string
+	route     string
+	proto     string
+	userAgent string
+	referer   string
+	requestID string
+	traceID   string
+	user      string
+	error     string
+	latency   time.Duration
+	bytesIn   int64
+	bytesOut  int
+	status    int
+}
+
+func (e *accessEntry) latencyMS() float64 {
+	return float64(e.latency.Microseconds()) / 1000
+}
+
+func (e *accessEntry) value(field string) string {
+	switch field {
+	case "time":
+		return e.time.Format(time.RFC3339)
+	case "client":
+		return e.client
+	case "method":
+		return e.method
+	case "path":
+		return e.path
+	case "route":
+		return e.route
+	case "proto":
+		return e.proto
+	case "status":
+		return strconv.Itoa(e.status)
+	case "latency_ms":
+		return strconv.FormatFloat(e.latencyMS(), 'f', 3, 64)
+	case "bytes_in":
+		return strconv.FormatInt(e.bytesIn, 10)
+	case "bytes_out":
+		return strconv.Itoa(e.bytesOut)
+	case "user_agent":
+		return e.userAgent
+	case "referer":
+		return e.referer
+	case "request_id":
+		return e.requestID
+	case "trace_id":
+		return e.traceID
+	case "user":
+		return e.user
+	case "error":
+		return e.error
+	}
+
+	return ""
+}
+
+// combined is the combined log format of Apache with the latency and the request ID
+func (e *accessEntry) combined() string {
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %d %q %q %sms %q",
+		e.client, orDash(e.user), e.time.Format("02/Jan/2006:15:04:05 -0700"), e.method, e.path, e.proto,
+		e.status, e.bytesOut, orDash(e.referer), orDash(e.userAgent), e.value("latency_ms"), orDash(e.requestID))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func (p *accessLogPolicy) write(e *accessEntry) {
+	switch p.conf.Format {
+	case AccessLogJSON:
+		fields := []zap.Field{
+			zap.String("client", e.client), zap.String("method", e.method), zap.String("path", e.path),
+			zap.String("route", e.route), zap.String("proto", e.proto), zap.Int("status", e.status),
+			zap.Float64("latency_ms", e.latencyMS()), zap.Int64("bytes_in", e.bytesIn), zap.Int("bytes_out", e.bytesOut),
+			zap.String("user_agent", e.userAgent), zap.String("referer", e.referer),
+			zap.String("request_id", e.requestID), zap.String("trace_id", e.traceID), zap.String("user", e.user),
+		}
+		if e.error != "" {
+			fields = append(fields, zap.String("error", e.error))
+		}
+		p.logger.Info("access", fields...)
+	case AccessLogTemplate:
+		p.logger.Info(accessLogField.ReplaceAllStringFunc(p.conf.Template, func(m string) string {
+			return e.value(m[1 : len(m)-1])
+		}))
+	default:
+		p.logger.Info(e.combined())
+	}
+}
+
+// countingBody counts the bytes of the request body read by the handlers
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// AccessLog logs every request as SetAccessLog configures, after the handlers.
+// Use it after RequestID so that the logs carry the request ID, the query of the path is redacted by SetRequestLog.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		begin := time.Now()
+		var body *countingBody
+		if c.Request.Body != nil {
+			body = &countingBody{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+		// the query is redacted as the request logs
+		path := getRequestLog().redactURI(c.Request.URL)
+
+		c.Next()
+
+		route := c.FullPath()
+		p := getAccessLog()
+		if !p.sampled(route, c.Writer.Status()) {
+			return
+		}
+
+		e := &accessEntry{
+			time:      begin,
+			latency:   time.Since(begin),
+			client:    c.ClientIP(),
+			method:    c.Request.Method,
+			path:      path,
+			route:     route,
+			proto:     c.Request.Proto,
+			status:    c.Writer.Status(),
+			bytesOut:  c.Writer.Size(),
+			userAgent: c.Request.UserAgent(),
+			referer:   c.Request.Referer(),
+			requestID: gadget.RequestID(c),
+			traceID:   logger.TraceID(c),
+			user:      GetOperator(c),
+			error:     strings.TrimSpace(c.Errors.ByType(gin.ErrorTypePrivate).String()),
+		}
+		if e.bytesOut < 0 {
+			e.bytesOut = 0
+		}
+		if body != nil {
+			e.bytesIn = body.n
+		}
+		if c.Request.ContentLength > e.bytesIn {
+			e.bytesIn = c.Request.ContentLength
+		}
+
+		p.write(e)
+	}
+}
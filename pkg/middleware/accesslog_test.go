@@ -0,0 +1,111 @@
+/*
+@Date: 2026/10/19 22:10
+@Author: yvanz
+@File : accesslog_test
+*/
+
+package middleware
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+func TestAccessLog(t *testing.T) {
+	old := getAccessLog().conf
+	defer func() {
+		_ = SetAccessLog(old)
+	}()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(RequestID(), AccessLog())
+	g.GET("/ping", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, "pong")
+	})
+	g.POST("/users/:id", func(c *gin.Context) {
+		_, _ = ioutil.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, "created")
+	})
+
+	serve := func(method, target, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("X-Request-ID", "req-1")
+		g.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	jsonFile := filepath.Join(t.TempDir(), "access.log")
+	if err := SetAccessLog(AccessLogConfig{
+		Format:      AccessLogJSON,
+		SampleRates: map[string]float64{"/ping": 0},
+		Sink:        logger.FileConfig{File: jsonFile},
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	serve(http.MethodGet, "/ping", "")
+	serve(http.MethodPost, "/users/1?token=t", "hello")
+	serve(http.MethodGet, "/ping?fail=1", "")
+
+	content, _ := ioutil.ReadFile(jsonFile)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("the sampled requests are logged: %s", content)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err.Error())
+	}
+	for k, v := range map[string]interface{}{
+		"msg": "access", "route": "/users/:id", "path": "/users/1?token=%2A%2A%2A%2A%2A%2A", "status": float64(201),
+		"bytes_in": float64(5), "bytes_out": float64(7), "request_id": "req-1",
+	} {
+		if entry[k] != v {
+			t.Errorf("%s: want %v, got %v", k, v, entry[k])
+		}
+	}
+	if _, ok := entry["latency_ms"].(float64); !ok {
+		t.Errorf("latency_ms is missing: %s", lines[0])
+	}
+
+	templateFile := filepath.Join(t.TempDir(), "access.log")
+	if err := SetAccessLog(AccessLogConfig{
+		Format:   AccessLogTemplate,
+		Template: "{method} {route} {status} {bytes_out} {request_id}",
+		Sink:     logger.FileConfig{File: templateFile},
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	serve(http.MethodGet, "/ping", "")
+	if content, _ = ioutil.ReadFile(templateFile); string(content) != "GET /ping 200 4 req-1\n" {
+		t.Errorf("got %q", content)
+	}
+
+	if errs := (AccessLogConfig{}).Validate(); len(errs) > 0 {
+		t.Errorf("the zero config is rejected: %v", errs)
+	}
+
+	for _, c := range []AccessLogConfig{
+		{Format: "xml"},
+		{Format: AccessLogTemplate, Template: "{unknown}"},
+		{SampleRates: map[string]float64{"/ping": 2}},
+	} {
+		if err := SetAccessLog(c); err == nil {
+			t.Errorf("%+v is accepted", c)
+		}
+	}
+}
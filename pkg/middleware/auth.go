@@ -0,0 +1,119 @@
+/*
+@Date: 2026/10/19 15:30
+@Author: yvanz
+@File : auth
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/jwtauth"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+const (
+	// ClaimsCtxKey is the key of the *jwtauth.Claims of the authenticated request
+	ClaimsCtxKey = "jwt_claims"
+	// OperatorCtxKey is the key of the operator of the authenticated request, see jwtauth.Config.OperatorClaim
+	OperatorCtxKey = "operator"
+)
+
+// Auth verifies the bearer token by jwtauth.Default, which is built by the server if auth is configured.
+// onFailure responds to the requests failed in authentication, which are aborted then.
+// A 401 response is sent if it is nil.
+func Auth(onFailure func(c *gin.Context, err error)) gin.HandlerFunc {
+	return AuthWithVerifier(nil, onFailure)
+}
+
+// AuthWithVerifier is the same as Auth but verifies by v, jwtauth.Default is used if v is nil
+func AuthWithVerifier(v *jwtauth.Verifier, onFailure func(c *gin.Context, err error)) gin.HandlerFunc {
+	if onFailure == nil {
+		onFailure = func(c *gin.Context, err error) {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		}
+	}
+
+	return func(c *gin.Context) {
+		verifier := v
+		if verifier == nil {
+			verifier = jwtauth.Default()
+		}
+		if verifier == nil {
+			onFailure(c, jwtauth.ErrNotConfigured)
+			c.Abort()
+			return
+		}
+
+		token := bearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			onFailure(c, jwtauth.ErrTokenMissing)
+			c.Abort()
+			return
+		}
+
+		claims, err := verifier.Verify(c.Request.Context(), token)
+		if err != nil {
+			onFailure(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(ClaimsCtxKey, claims)
+		c.Set(OperatorCtxKey, claims.Operator)
+		logger.WithContext(c, OperatorCtxKey, claims.Operator)
+		c.Next()
+	}
+}
+
+func bearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+// GetClaims returns the claims of the request authenticated by Auth
+func GetClaims(c *gin.Context) (*jwtauth.Claims, bool) {
+	v, ok := c.Get(ClaimsCtxKey)
+	if !ok {
+		return nil, false
+	}
+
+	claims, ok := v.(*jwtauth.Claims)
+	return claims, ok
+}
+
+// authenticatedUser returns the operator authenticated by Auth or the client certificate
+func authenticatedUser(c *gin.Context) string {
+	if operator := c.GetString(OperatorCtxKey); operator != "" {
+		return operator
+	}
+
+	if identity, ok := GetClientIdentity(c); ok {
+		return identity.Name()
+	}
+
+	return ""
+}
+
+// GetOperator returns who sends the request, for request logs and audit records.
+// It prefers the operator authenticated by Auth, then the identity of the verified client certificate.
+// X-Forwarded-User is trusted only if neither is present, so it must be set by a trusted proxy.
+func GetOperator(c *gin.Context) string {
+	if operator := authenticatedUser(c); operator != "" {
+		return operator
+	}
+
+	if re, ok := c.Request.Header["X-Forwarded-User"]; ok {
+		return re[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,74 @@
+/*
+@Date: 2026/10/19 16:20
+@Author: yvanz
+@File : auth_test
+*/
+
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/jwtauth"
+)
+
+func hs256(secret, payload string) string {
+	enc := base64.RawURLEncoding
+	input := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+
+	return input + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuth(t *testing.T) {
+	v, err := jwtauth.NewVerifier(jwtauth.Config{Algorithms: []string{"HS256"}, HMACSecret: "secret", OperatorClaim: "name"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(AuthWithVerifier(v, nil))
+	g.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, GetOperator(c))
+	})
+
+	exp := time.Now().Add(time.Hour).Unix()
+	for _, tc := range []struct {
+		header string
+		status int
+		body   string
+	}{
+		{"Bearer " + hs256("secret", fmt.Sprintf(`{"sub":"u-1","name":"alice","exp":%d}`, exp)), http.StatusOK, "alice"},
+		{"bearer " + hs256("secret", fmt.Sprintf(`{"sub":"u-1","exp":%d}`, exp)), http.StatusOK, "u-1"},
+		{"Bearer " + hs256("secret", `{"sub":"u-1"}`), http.StatusUnauthorized, ""},
+		{"Bearer " + hs256("guess", fmt.Sprintf(`{"sub":"u-1","exp":%d}`, exp)), http.StatusUnauthorized, ""},
+		{"Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		req.Header.Set("Authorization", tc.header)
+		req.Header.Set("X-Forwarded-User", "mallory")
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: want %d, got %d", tc.header, tc.status, w.Code)
+			continue
+		}
+		if tc.status == http.StatusOK && w.Body.String() != tc.body {
+			t.Errorf("%s: want operator %s, got %s", tc.header, tc.body, w.Body.String())
+		}
+		if tc.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: WWW-Authenticate is missing", tc.header)
+		}
+	}
+}
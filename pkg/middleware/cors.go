@@ -0,0 +1,215 @@
+/*
+@Date: 2026/10/19 13:10
+@Author: yvanz
+@File : cors
+*/
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	corsAnyOrigin = "*"
+	// corsRegexPrefix marks an allowed origin as a regular expression, e.g. ~^https://[a-z]+\.example\.com$
+	corsRegexPrefix = "~"
+)
+
+var _cors atomic.Value
+
+func init() {
+	p, _ := newCorsPolicy(CorsConfig{
+		AllowOrigins: []string{corsAnyOrigin},
+		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders: []string{"Content-Type", "AccessToken", "X-CSRF-Token", "Authorization", "Token"},
+	})
+	_cors.Store(p)
+}
+
+// CorsConfig is the CORS policy of the API engine.
+// An allowed origin is exact such as https://app.example.com, a wildcard subdomain such as https://*.example.com,
+// a regular expression starting with ~, or * for any origin.
+type CorsConfig struct {
+	AllowOrigins     []string      `yaml:"allow_origins" env:"CorsAllowOrigins" env-default:"*" env-description:"allowed origins: exact, https://*.example.com, ~regexp or *" json:"allow_origins,omitempty"`
+	AllowMethods     []string      `yaml:"allow_methods" env:"CorsAllowMethods" env-default:"GET,POST,PUT,PATCH,DELETE,OPTIONS" env-description:"allowed methods of cross-origin requests" json:"allow_methods,omitempty"`
+	AllowHeaders     []string      `yaml:"allow_headers" env:"CorsAllowHeaders" env-default:"Content-Type,AccessToken,X-CSRF-Token,Authorization,Token" env-description:"allowed request headers, * to allow the requested ones" json:"allow_headers,omitempty"`
+	ExposeHeaders    []string      `yaml:"expose_headers" env:"CorsExposeHeaders" env-description:"response headers exposed to browsers" json:"expose_headers,omitempty"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CorsMaxAge" env-default:"10m" env-description:"how long the result of a preflight request can be cached" json:"max_age,omitempty"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CorsAllowCredentials" env-description:"allow cookies and authorization headers of cross-origin requests" json:"allow_credentials,omitempty"`
+}
+
+// Validate returns all problems of the config
+func (c CorsConfig) Validate() (errs []error) {
+	for _, o := range c.AllowOrigins {
+		if o == corsAnyOrigin && c.AllowCredentials {
+			errs = append(errs, fmt.Errorf("allow_origins must not contain * when allow_credentials is true, list the origins instead"))
+		}
+
+		if strings.HasPrefix(o, corsRegexPrefix) {
+			if _, err := regexp.Compile(strings.TrimPrefix(o, corsRegexPrefix)); err != nil {
+				errs = append(errs, fmt.Errorf("invalid origin regexp %s: %s", o, err.Error()))
+			}
+		}
+	}
+
+	if c.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("max_age must not be negative"))
+	}
+
+	return errs
+}
+
+type corsPolicy struct {
+	exact         map[string]bool
+	patterns      []*regexp.Regexp
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+	anyOrigin     bool
+	anyHeader     bool
+	credentials   bool
+}
+
+func newCorsPolicy(c CorsConfig) (*corsPolicy, error) {
+	if errs := c.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	p := &corsPolicy{
+		exact:         make(map[string]bool),
+		allowMethods:  strings.Join(c.AllowMethods, ", "),
+		exposeHeaders: strings.Join(c.ExposeHeaders, ", "),
+		credentials:   c.AllowCredentials,
+	}
+	if c.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(c.MaxAge.Seconds()))
+	}
+
+	for _, h := range c.AllowHeaders {
+		if h == "*" {
+			p.anyHeader = true
+		}
+	}
+	p.allowHeaders = strings.Join(c.AllowHeaders, ", ")
+
+	for _, o := range c.AllowOrigins {
+		switch {
+		case o == corsAnyOrigin:
+			p.anyOrigin = true
+		case strings.HasPrefix(o, corsRegexPrefix):
+			p.patterns = append(p.patterns, regexp.MustCompile(strings.TrimPrefix(o, corsRegexPrefix)))
+		case strings.Contains(o, "*"):
+			// https://*.example.com matches any subdomain but not example.com itself
+			pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(o)), `\*`, `[a-z0-9-]+(\.[a-z0-9-]+)*`) + "$"
+			p.patterns = append(p.patterns, regexp.MustCompile(pattern))
+		default:
+			p.exact[strings.ToLower(o)] = true
+		}
+	}
+
+	return p, nil
+}
+
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.exact[origin] {
+		return true
+	}
+
+	for _, re := range p.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetCors replaces the CORS policy used by Cors, it could be called at any time to apply a new config
+func SetCors(c CorsConfig) error {
+	p, err := newCorsPolicy(c)
+	if err != nil {
+		return err
+	}
+
+	_cors.Store(p)
+	return nil
+}
+
+// Cors handles cross-origin requests by the policy set by SetCors, any origin is allowed without credentials by default.
+// Only matched origins are echoed back, preflight requests of other origins are rejected with 403.
+func Cors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		p := _cors.Load().(*corsPolicy)
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		h := c.Writer.Header()
+		// the response depends on the origin unless any origin gets *, whether it is allowed or not
+		echoOrigin := !p.anyOrigin || p.credentials
+		if echoOrigin {
+			h.Add("Vary", "Origin")
+		}
+
+		if !p.allowOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		if echoOrigin {
+			h.Set("Access-Control-Allow-Origin", origin)
+		} else {
+			h.Set("Access-Control-Allow-Origin", corsAnyOrigin)
+		}
+		if p.credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if p.exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", p.exposeHeaders)
+			}
+
+			c.Next()
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", p.allowMethods)
+		if p.anyHeader {
+			h.Set("Access-Control-Allow-Headers", c.GetHeader("Access-Control-Request-Headers"))
+		} else if p.allowHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", p.allowHeaders)
+		}
+		if p.maxAge != "" {
+			h.Set("Access-Control-Max-Age", p.maxAge)
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+@Date: 2026/10/19 13:40
+@Author: yvanz
+@File : cors_test
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCors(t *testing.T) {
+	if err := SetCors(CorsConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}); err == nil {
+		t.Error("* with credentials should be rejected")
+	}
+
+	err := SetCors(CorsConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.example.org", `~^http://localhost:\d+$`},
+		AllowMethods:     []string{"GET", "POST"},
+		AllowHeaders:     []string{"Authorization"},
+		ExposeHeaders:    []string{"X-Request-ID"},
+		MaxAge:           time.Hour,
+		AllowCredentials: true,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		_ = SetCors(CorsConfig{AllowOrigins: []string{"*"}})
+	}()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(Cors())
+	g.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+
+	for _, tc := range []struct {
+		origin    string
+		preflight bool
+		status    int
+		allowed   bool
+	}{
+		{"https://app.example.com", false, http.StatusOK, true},
+		{"https://app.example.com", true, http.StatusNoContent, true},
+		{"https://a.b.example.org", false, http.StatusOK, true},
+		{"https://example.org", false, http.StatusOK, false},
+		{"http://localhost:3000", true, http.StatusNoContent, true},
+		{"https://evil.com", false, http.StatusOK, false},
+		{"https://evil.com", true, http.StatusForbidden, false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		if tc.preflight {
+			req = httptest.NewRequest(http.MethodOptions, "/ping", nil)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		req.Header.Set("Origin", tc.origin)
+
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s preflight %v: want %d, got %d", tc.origin, tc.preflight, tc.status, w.Code)
+		}
+
+		allowOrigin := w.Header().Get("Access-Control-Allow-Origin")
+		if tc.allowed && (allowOrigin != tc.origin || w.Header().Get("Access-Control-Allow-Credentials") != "true") {
+			t.Errorf("%s should be echoed back with credentials, got %v", tc.origin, w.Header())
+		}
+		if !tc.allowed && allowOrigin != "" {
+			t.Errorf("%s should not be allowed, got %s", tc.origin, allowOrigin)
+		}
+		if vary := w.Header().Values("Vary"); len(vary) == 0 || vary[0] != "Origin" {
+			t.Errorf("%s preflight %v: want Vary: Origin, got %v", tc.origin, tc.preflight, vary)
+		}
+		if tc.allowed && tc.preflight && w.Header().Get("Access-Control-Max-Age") != "3600" {
+			t.Errorf("want max age 3600, got %v", w.Header())
+		}
+	}
+}
@@ -2,42 +2,48 @@ package middleware
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
 	"github.com/yvanz/gin-tmpl/pkg/gadget"
-	"github.com/yvanz/gin-tmpl/pkg/logger"
 )
 
 type httpReqResLog struct {
-	Operator   string `json:"operator"`
-	URI        string `json:"uri"`
-	Method     string `json:"method"`
-	Params     string `json:"params"`
-	Client     string `json:"client"`
-	Response   string `json:"response"`
-	StatusCode int    `json:"status_code"`
+	Headers    map[string]string `json:"headers"`
+	Operator   string            `json:"operator"`
+	URI        string            `json:"uri"`
+	Method     string            `json:"method"`
+	Params     string            `json:"params"`
+	Client     string            `json:"client"`
+	Response   string            `json:"response"`
+	StatusCode int               `json:"status_code"`
 }
 
+// bodyLogWriter keeps the head of the response, one byte over limit to tell whether it is truncated
 type bodyLogWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body  *bytes.Buffer
+	limit int
 }
 
-func (b bodyLogWriter) Write(bs []byte) (int, error) {
-	b.body.Write(bs)
+func newBodyLogWriter(w gin.ResponseWriter, limit int) *bodyLogWriter {
+	return &bodyLogWriter{ResponseWriter: w, body: bytes.NewBufferString(""), limit: limit}
+}
+
+func (b *bodyLogWriter) Write(bs []byte) (int, error) {
+	if n := b.limit + 1 - b.body.Len(); n > 0 {
+		if n > len(bs) {
+			n = len(bs)
+		}
+		b.body.Write(bs[:n])
+	}
 	return b.ResponseWriter.Write(bs)
 }
 
 func GinInterceptorWithTrace(tra opentracing.Tracer, isResponse bool, ignoreURI ...string) gin.HandlerFunc { //nolint:funlen
 	return func(c *gin.Context) {
-		params := make(map[string]interface{})
+		p := getRequestLog()
 		_ = c.Request.ParseForm()
 
 		requestURI := c.FullPath()
@@ -62,109 +68,53 @@ func GinInterceptorWithTrace(tra opentracing.Tracer, isResponse bool, ignoreURI
 			c.Set(gadget.SpanCtxKey, newCtx)
 		}
 
-		for k, v := range c.Request.Form {
-			params[k] = v
-		}
-
-		for k, v := range c.Request.PostForm {
-			params[k] = v
-		}
-
-		par, _ := json.Marshal(params)
-
-		var bodyBytes []byte
-		if c.Request.Body != nil {
-			bodyBytes, _ = ioutil.ReadAll(c.Request.Body)
-		}
-		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
-
-		if len(bodyBytes) > 0 {
-			par = bodyBytes
+		par := p.params(c.Request)
+		if body := p.requestBody(c.Request); body != "" {
+			par = body
 		}
 
 		lg := &httpReqResLog{
-			Operator: getRequestUser(c.Request.Header),
-			URI:      c.Request.URL.Path, Method: c.Request.Method,
-			Params: string(par), Client: c.ClientIP(),
+			URI: c.Request.URL.Path, Method: c.Request.Method,
+			Params: par, Client: c.ClientIP(),
+			Headers: p.requestHeaders(c.Request.Header),
 		}
 
-		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		blw := newBodyLogWriter(c.Writer, p.conf.MaxBodySize)
 		c.Writer = blw
 		c.Next()
 
+		// the operator is known after the auth middleware, which may run after this one
+		lg.Operator = GetOperator(c)
 		lg.StatusCode = c.Writer.Status()
+		response := p.response(blw)
 		if isResponse {
-			lg.Response = blw.body.String()
+			lg.Response = response
 		}
 
-		logBytes, _ := json.Marshal(&lg)
-		logger.Debugf("request details: %s", string(logBytes))
+		p.write(c, lg)
 
 		if span != nil {
 			span.LogFields(
 				log.String("uri", lg.URI), log.String("method", lg.Method),
 				log.String("client", c.ClientIP()), log.String("params", lg.Params),
-				log.Int("code", lg.StatusCode), log.String("response", blw.body.String()),
+				log.Int("code", lg.StatusCode), log.String("response", response),
 			)
 		}
 	}
 }
 
-func Cors() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		method := c.Request.Method
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, AccessToken, X-CSRF-Token, Authorization, Token")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
-		c.Header("Access-Control-Allow-Credentials", "true")
-
-		if method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-		}
-
-		c.Next()
-	}
-}
-
+// GinFormatterLog logs the access of the requests.
+//
+// Deprecated: use AccessLog, whose format is configured by SetAccessLog.
 func GinFormatterLog() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(params gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s %d \"%s\" \"%s\" \"\n",
-			params.ClientIP,
-			params.TimeStamp.Format(time.RFC1123),
-			params.Method,
-			params.Path,
-			params.Request.Proto,
-			params.StatusCode,
-			params.Latency,
-			params.BodySize,
-			params.Request.UserAgent(),
-			params.ErrorMessage,
-		)
-	})
-}
-
-func getRequestUser(header http.Header) string {
-	if re, ok := header["X-Forwarded-User"]; ok {
-		return re[0]
-	}
-
-	return ""
+	return AccessLog()
 }
 
 // GinInterceptor 用于拦截请求和响应并也写入日志
 func GinInterceptor(isResponse bool, ignoreURI ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		params := make(map[string]interface{})
-
+		p := getRequestLog()
 		_ = c.Request.ParseForm()
-		for k, v := range c.Request.Form {
-			params[k] = v
-		}
-
-		for k, v := range c.Request.PostForm {
-			params[k] = v
-		}
 
 		requestURI := c.FullPath()
 		ignore := false
@@ -174,38 +124,33 @@ func GinInterceptor(isResponse bool, ignoreURI ...string) gin.HandlerFunc {
 			}
 		}
 
-		var par []byte
+		var par string
 		if !ignore {
-			par, _ = json.Marshal(params)
-			var bodyBytes []byte
-			if c.Request.Body != nil {
-				bodyBytes, _ = ioutil.ReadAll(c.Request.Body)
-			}
-			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
-
-			if len(bodyBytes) > 0 {
-				par = bodyBytes
+			par = p.params(c.Request)
+			if body := p.requestBody(c.Request); body != "" {
+				par = body
 			}
 		}
 
 		lg := &httpReqResLog{
-			Operator: getRequestUser(c.Request.Header),
-			URI:      c.Request.RequestURI,
-			Method:   c.Request.Method,
-			Params:   string(par),
-			Client:   c.ClientIP(),
+			URI:     p.redactURI(c.Request.URL),
+			Method:  c.Request.Method,
+			Params:  par,
+			Client:  c.ClientIP(),
+			Headers: p.requestHeaders(c.Request.Header),
 		}
 
-		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		blw := newBodyLogWriter(c.Writer, p.conf.MaxBodySize)
 		c.Writer = blw
 		c.Next()
 
+		// the operator is known after the auth middleware, which may run after this one
+		lg.Operator = GetOperator(c)
 		lg.StatusCode = c.Writer.Status()
 		if isResponse {
-			lg.Response = blw.body.String()
+			lg.Response = p.response(blw)
 		}
 
-		logBytes, _ := json.Marshal(&lg)
-		logger.Debugf("request details: %s", string(logBytes))
+		p.write(c, lg)
 	}
 }
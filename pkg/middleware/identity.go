@@ -0,0 +1,87 @@
+/*
+@Date: 2026/10/18 22:30
+@Author: yvanz
+@File : identity
+*/
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+const ClientIdentityCtxKey = "client_identity"
+
+// ClientIdentity is the subject of a verified client certificate
+type ClientIdentity struct {
+	CommonName     string   `json:"common_name,omitempty"`
+	DNSNames       []string `json:"dns_names,omitempty"`
+	EmailAddresses []string `json:"email_addresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	IPAddresses    []string `json:"ip_addresses,omitempty"`
+}
+
+// Name returns the common name, or the first SAN if the common name is empty
+func (i *ClientIdentity) Name() string {
+	if i.CommonName != "" {
+		return i.CommonName
+	}
+
+	for _, names := range [][]string{i.DNSNames, i.URIs, i.EmailAddresses, i.IPAddresses} {
+		if len(names) > 0 {
+			return names[0]
+		}
+	}
+
+	return ""
+}
+
+// ClientCert sets the ClientIdentity of the verified client certificate in the gin context by ClientIdentityCtxKey
+func ClientCert() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if identity := clientIdentity(c.Request); identity != nil {
+			c.Set(ClientIdentityCtxKey, identity)
+			if name := identity.Name(); name != "" {
+				logger.WithContext(c, OperatorCtxKey, name)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// GetClientIdentity returns the identity of the verified client certificate, ok is false if there is none
+func GetClientIdentity(c *gin.Context) (identity *ClientIdentity, ok bool) {
+	if v, exists := c.Get(ClientIdentityCtxKey); exists {
+		identity, ok = v.(*ClientIdentity)
+		return
+	}
+
+	identity = clientIdentity(c.Request)
+	return identity, identity != nil
+}
+
+// clientIdentity only trusts certificates verified during the handshake
+func clientIdentity(r *http.Request) *ClientIdentity {
+	if r == nil || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	identity := &ClientIdentity{
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+	for _, u := range cert.URIs {
+		identity.URIs = append(identity.URIs, u.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		identity.IPAddresses = append(identity.IPAddresses, ip.String())
+	}
+
+	return identity
+}
@@ -0,0 +1,113 @@
+/*
+@Date: 2026/10/18 11:30
+@Author: yvanz
+@File : metrics
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RetCodeCtxKey is the key of the ret_code in the response envelope, set by the handler via SetRetCode
+const RetCodeCtxKey = "ret_code"
+
+const (
+	unmatchedRoute = "unmatched"
+	otherMethod    = "OTHER"
+)
+
+var (
+	registerMetricsOnce sync.Once
+
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests handled by the API engine.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests handled by the API engine.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	httpRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests being handled by the API engine.",
+	}, []string{"route", "method"})
+
+	httpResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_response_size_bytes",
+		Help:    "Size of HTTP responses written by the API engine.",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, []string{"route", "method"})
+
+	httpResponseRetCode = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_response_ret_code_total",
+		Help: "Total number of responses grouped by the ret_code of the response envelope.",
+	}, []string{"route", "method", "ret_code"})
+)
+
+// standardMethods are the method labels, the others are labelled OTHER to keep the cardinality low
+var standardMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+func methodLabel(method string) string {
+	if standardMethods[method] {
+		return method
+	}
+
+	return otherMethod
+}
+
+// SetRetCode records the ret_code of the response envelope so that it can be picked up by Metrics
+func SetRetCode(c *gin.Context, code int) {
+	c.Set(RetCodeCtxKey, code)
+}
+
+// Metrics records request count, latency, in-flight requests, response size and ret_code,
+// labelled by the route template instead of the raw path and by the standard methods to keep the cardinality low.
+func Metrics() gin.HandlerFunc {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(httpRequestsTotal, httpRequestDuration, httpRequestsInFlight,
+			httpResponseSize, httpResponseRetCode)
+	})
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := methodLabel(c.Request.Method)
+
+		inFlight := httpRequestsInFlight.WithLabelValues(route, method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		begin := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequestsTotal.WithLabelValues(route, method, status).Inc()
+		httpRequestDuration.WithLabelValues(route, method, status).Observe(time.Since(begin).Seconds())
+
+		if size := c.Writer.Size(); size > 0 {
+			httpResponseSize.WithLabelValues(route, method).Observe(float64(size))
+		}
+
+		if code, ok := c.Get(RetCodeCtxKey); ok {
+			if retCode, ok := code.(int); ok {
+				httpResponseRetCode.WithLabelValues(route, method, strconv.Itoa(retCode)).Inc()
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+@Date: 2026/10/19 22:30
+@Author: yvanz
+@File : metrics_test
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sampleCount returns the number of the observations of a histogram
+func sampleCount(t *testing.T, o prometheus.Observer) uint64 {
+	var m dto.Metric
+	if err := o.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(Metrics())
+
+	var inFlight float64
+	g.GET("/users/:id", func(c *gin.Context) {
+		inFlight = testutil.ToFloat64(httpRequestsInFlight.WithLabelValues("/users/:id", http.MethodGet))
+		SetRetCode(c, 1001)
+		c.String(http.StatusOK, "hello")
+	})
+	g.Handle("PURGE", "/cache", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	count := func(route, method, status string) float64 {
+		return testutil.ToFloat64(httpRequestsTotal.WithLabelValues(route, method, status))
+	}
+	retCodes := func() float64 {
+		return testutil.ToFloat64(httpResponseRetCode.WithLabelValues("/users/:id", http.MethodGet, "1001"))
+	}
+
+	requests, unmatched, others := count("/users/:id", http.MethodGet, "200"), count(unmatchedRoute, http.MethodGet, "404"),
+		count("/cache", otherMethod, "204")
+	latencies := sampleCount(t, httpRequestDuration.WithLabelValues("/users/:id", http.MethodGet, "200"))
+	sizes := sampleCount(t, httpResponseSize.WithLabelValues("/users/:id", http.MethodGet))
+	codes := retCodes()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodGet, "/missing", nil),
+		httptest.NewRequest("PURGE", "/cache", nil),
+	} {
+		g.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if n := count("/users/:id", http.MethodGet, "200"); n != requests+2 {
+		t.Errorf("want 2 more requests of the route, got %v", n-requests)
+	}
+	if n := count(unmatchedRoute, http.MethodGet, "404"); n != unmatched+1 {
+		t.Errorf("want 1 more unmatched request, got %v", n-unmatched)
+	}
+	if n := count("/cache", otherMethod, "204"); n != others+1 {
+		t.Errorf("want the non-standard method labelled %s, got %v more", otherMethod, n-others)
+	}
+	if n := sampleCount(t, httpRequestDuration.WithLabelValues("/users/:id", http.MethodGet, "200")); n != latencies+2 {
+		t.Errorf("want 2 more latencies, got %d", n-latencies)
+	}
+	if n := sampleCount(t, httpResponseSize.WithLabelValues("/users/:id", http.MethodGet)); n != sizes+2 {
+		t.Errorf("want 2 more response sizes, got %d", n-sizes)
+	}
+	if n := retCodes(); n != codes+2 {
+		t.Errorf("want 2 more ret codes, got %v", n-codes)
+	}
+
+	if inFlight != 1 {
+		t.Errorf("want 1 request in flight in the handler, got %v", inFlight)
+	}
+	if n := testutil.ToFloat64(httpRequestsInFlight.WithLabelValues("/users/:id", http.MethodGet)); n != 0 {
+		t.Errorf("want no request in flight after the handler, got %v", n)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+@Date: 2026/10/19 19:00
+@Author: yvanz
+@File : ratelimit
+*/
+
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/ratelimit"
+)
+
+// RateLimit limits the requests by the limiter named name in the rate_limit config, the requests go on if it is not configured.
+// It sets X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (in seconds), and Retry-After if a request is limited.
+// onLimited responds to the limited requests, which are aborted then. A 429 response is sent if it is nil.
+// Use it after Auth if the requests are counted by user.
+func RateLimit(name string, onLimited func(c *gin.Context, err error)) gin.HandlerFunc {
+	if onLimited == nil {
+		onLimited = func(c *gin.Context, err error) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": err.Error()})
+		}
+	}
+
+	return func(c *gin.Context) {
+		l := ratelimit.Get(name)
+		if l == nil {
+			c.Next()
+			return
+		}
+
+		res := l.Allow(c.Request.Context(), rateLimitKey(c, l.Config()))
+
+		h := c.Writer.Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("X-RateLimit-Reset", ceilSeconds(res.ResetAfter))
+		if !res.Allowed {
+			h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			onLimited(c, ratelimit.ErrLimited)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitKey(c *gin.Context, conf ratelimit.Config) string {
+	parts := make([]string, 0, len(conf.KeyBy))
+	for _, by := range conf.KeyBy {
+		var part string
+		switch by {
+		case ratelimit.KeyUser:
+			if user := authenticatedUser(c); user != "" {
+				part = "user=" + user
+			}
+		case ratelimit.KeyAPIKey:
+			// keep the API keys out of redis
+			if key := c.GetHeader(conf.APIKeyHeader); key != "" {
+				sum := sha256.Sum256([]byte(key))
+				part = "key=" + hex.EncodeToString(sum[:16])
+			}
+		case ratelimit.KeyRoute:
+			part = "route=" + c.Request.Method + " " + c.FullPath()
+		}
+
+		if part == "" {
+			part = "ip=" + c.ClientIP()
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
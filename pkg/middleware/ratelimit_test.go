@@ -0,0 +1,64 @@
+/*
+@Date: 2026/10/19 19:20
+@Author: yvanz
+@File : ratelimit_test
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	err := ratelimit.Set(map[string]ratelimit.Config{
+		"demo": {Limit: 1, Period: time.Minute, KeyBy: []string{ratelimit.KeyAPIKey}},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		_ = ratelimit.Set(nil)
+	}()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.GET("/demo", RateLimit("demo", nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	g.GET("/free", RateLimit("unknown", nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		path       string
+		apiKey     string
+		status     int
+		retryAfter string
+	}{
+		{"/demo", "k1", http.StatusOK, ""},
+		{"/demo", "k1", http.StatusTooManyRequests, "60"},
+		{"/demo", "k2", http.StatusOK, ""},
+		{"/free", "k1", http.StatusOK, ""},
+		{"/free", "k1", http.StatusOK, ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("X-API-Key", tc.apiKey)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if w.Code != tc.status || w.Header().Get("Retry-After") != tc.retryAfter {
+			t.Errorf("%s %s: want %d with Retry-After %q, got %d with %q",
+				tc.path, tc.apiKey, tc.status, tc.retryAfter, w.Code, w.Header().Get("Retry-After"))
+		}
+		if tc.path == "/demo" && w.Header().Get("X-RateLimit-Limit") != "1" {
+			t.Errorf("%s %s: X-RateLimit-Limit is %q", tc.path, tc.apiKey, w.Header().Get("X-RateLimit-Limit"))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+@Date: 2026/10/19 17:30
+@Author: yvanz
+@File : rbac
+*/
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"github.com/yvanz/gin-tmpl/pkg/rbac"
+)
+
+// Authorize checks the method and the route path of the request against the roles of the operator by rbac.Default,
+// which is built by the server if rbac is configured. Use it after Auth if the roles come from the token.
+// onDenied responds to the denied requests, which are aborted then. A 403 response is sent if it is nil.
+// In dry-run mode the denials are logged and the requests go on.
+func Authorize(onDenied func(c *gin.Context, err error)) gin.HandlerFunc {
+	return authorize(onDenied, func(e *rbac.Enforcer, roles []string, c *gin.Context) error {
+		return e.Authorize(roles, c.Request.Method, c.FullPath())
+	})
+}
+
+// RequirePermission is the same as Authorize but checks the named permission of the policy rather than the route
+func RequirePermission(permission string, onDenied func(c *gin.Context, err error)) gin.HandlerFunc {
+	return authorize(onDenied, func(e *rbac.Enforcer, roles []string, _ *gin.Context) error {
+		return e.Require(roles, permission)
+	})
+}
+
+func authorize(onDenied func(c *gin.Context, err error), check func(*rbac.Enforcer, []string, *gin.Context) error) gin.HandlerFunc {
+	if onDenied == nil {
+		onDenied = func(c *gin.Context, err error) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		}
+	}
+
+	return func(c *gin.Context) {
+		e := rbac.Default()
+		if e == nil {
+			onDenied(c, rbac.ErrNotConfigured)
+			c.Abort()
+			return
+		}
+
+		var raw map[string]interface{}
+		if claims, ok := GetClaims(c); ok {
+			raw = claims.Raw
+		}
+
+		// the roles are granted to the verified identities only, never to X-Forwarded-User
+		operator := authenticatedUser(c)
+		if err := check(e, e.Roles(operator, raw), c); err != nil {
+			if e.DryRun() {
+				logger.WarnfWithTrace(c, "rbac dry run, %q would be denied: %s", operator, err.Error())
+				c.Next()
+				return
+			}
+
+			onDenied(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+/*
+@Date: 2026/10/19 18:00
+@Author: yvanz
+@File : rbac_test
+*/
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/rbac"
+)
+
+func TestAuthorize(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rbac.yaml")
+	policy := "permissions:\n  demo:read: [\"* /demo/:id\"]\nroles:\n  viewer: [demo:read]\nsubjects:\n  alice: [viewer]\n"
+	if err := os.WriteFile(file, []byte(policy), 0o600); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := rbac.Init(context.Background(), rbac.Config{File: file}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	// stands for Auth, which sets the verified operator
+	g.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set(OperatorCtxKey, user)
+		}
+	}, Authorize(nil))
+	g.GET("/demo/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	g.DELETE("/demo/:id", RequirePermission("demo:write", nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		user      string
+		forwarded string
+		method    string
+		dryRun    bool
+		status    int
+	}{
+		{"alice", "", http.MethodGet, false, http.StatusOK},
+		{"bob", "", http.MethodGet, false, http.StatusForbidden},
+		{"bob", "", http.MethodGet, true, http.StatusOK},
+		{"alice", "", http.MethodDelete, false, http.StatusForbidden},
+		// the header sent by the client grants no roles
+		{"", "alice", http.MethodGet, false, http.StatusForbidden},
+		{"bob", "alice", http.MethodGet, false, http.StatusForbidden},
+	} {
+		rbac.Default().SetDryRun(tc.dryRun)
+
+		req := httptest.NewRequest(tc.method, "/demo/1", nil)
+		req.Header.Set("X-Test-User", tc.user)
+		req.Header.Set("X-Forwarded-User", tc.forwarded)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s (forwarded %s) %s (dry run %v): want %d, got %d", tc.user, tc.forwarded, tc.method, tc.dryRun, tc.status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+/*
+@Date: 2026/10/19 21:20
+@Author: yvanz
+@File : reqlog
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const redactedValue = "******"
+
+var (
+	_requestLog atomic.Value
+	// serializes SetRequestLog, which closes the replaced sink
+	requestLogLock sync.Mutex
+)
+
+func init() {
+	p, _ := newRequestLogPolicy(RequestLogConfig{
+		RedactKeys:    []string{"password", "passwd", "secret", "token", "access_token", "refresh_token", "authorization", "api_key"},
+		RedactHeaders: []string{"Authorization", "Cookie", "Set-Cookie", "X-API-Key"},
+		ContentTypes:  []string{"application/json", "application/x-www-form-urlencoded", "text/"},
+		MaxBodySize:   4096,
+	})
+	_requestLog.Store(p)
+}
+
+// RequestLogConfig configures the request and response details logged by GinInterceptor and GinInterceptorWithTrace.
+// The keys are redacted in JSON bodies at any depth, form and query parameters, case-insensitively.
+// The paths are dotted JSON paths from the root such as user.cards.*.number, * matches any key or array index.
+// Only the bodies of the content types are captured, an entry ending with / matches the prefix such as text/.
+type RequestLogConfig struct {
+	RedactKeys    []string          `yaml:"redact_keys" env:"RequestLogRedactKeys" env-default:"password,passwd,secret,token,access_token,refresh_token,authorization,api_key" env-description:"keys redacted in JSON bodies, form and query parameters" json:"redact_keys,omitempty"`
+	RedactPaths   []string          `yaml:"redact_paths" env:"RequestLogRedactPaths" env-description:"dotted JSON paths redacted in bodies, * for any key or index" json:"redact_paths,omitempty"`
+	RedactHeaders []string          `yaml:"redact_headers" env:"RequestLogRedactHeaders" env-default:"Authorization,Cookie,Set-Cookie,X-API-Key" env-description:"request headers redacted in the logs" json:"redact_headers,omitempty"`
+	ContentTypes  []string          `yaml:"content_types" env:"RequestLogContentTypes" env-default:"application/json,application/x-www-form-urlencoded,text/" env-description:"content types of the bodies captured in the logs" json:"content_types,omitempty"`
+	Sink          logger.FileConfig `yaml:"sink" json:"sink,omitempty" reload:"restart"`
+	MaxBodySize   int               `yaml:"max_body_size" env:"RequestLogMaxBodySize" env-default:"4096" env-description:"bytes of the bodies captured in the logs, 0 to capture none" json:"max_body_size,omitempty"`
+}
+
+// Validate returns all problems of the config
+func (c RequestLogConfig) Validate() (errs []error) {
+	if c.MaxBodySize < 0 {
+		errs = append(errs, fmt.Errorf("max_body_size must not be negative"))
+	}
+
+	for _, p := range c.RedactPaths {
+		if p == "" || strings.HasPrefix(p, ".") || strings.HasSuffix(p, ".") || strings.Contains(p, "..") {
+			errs = append(errs, fmt.Errorf("invalid redact path %q", p))
+		}
+	}
+
+	return append(errs, c.Sink.Validate()...)
+}
+
+type requestLogPolicy struct {
+	keys         map[string]bool
+	headers      map[string]bool
+	paths        [][]string
+	contentTypes []string
+	// redacts the bodies which could not be parsed, such as the truncated ones
+	jsonPattern *regexp.Regexp
+	formPattern *regexp.Regexp
+	sink        *zap.Logger
+	closer      io.Closer
+	conf        RequestLogConfig
+}
+
+func newRequestLogPolicy(c RequestLogConfig) (*requestLogPolicy, error) {
+	if errs := c.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	p := &requestLogPolicy{
+		keys:    make(map[string]bool),
+		headers: make(map[string]bool),
+		conf:    c,
+	}
+
+	patternKeys := make([]string, 0, len(c.RedactKeys)+len(c.RedactPaths))
+	for _, k := range c.RedactKeys {
+		p.keys[strings.ToLower(k)] = true
+		patternKeys = append(patternKeys, regexp.QuoteMeta(k))
+	}
+	for _, path := range c.RedactPaths {
+		segments := strings.Split(path, ".")
+		p.paths = append(p.paths, segments)
+		if last := segments[len(segments)-1]; last != "*" {
+			patternKeys = append(patternKeys, regexp.QuoteMeta(last))
+		}
+	}
+	for _, h := range c.RedactHeaders {
+		p.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, t := range c.ContentTypes {
+		p.contentTypes = append(p.contentTypes, strings.ToLower(t))
+	}
+
+	if len(patternKeys) > 0 {
+		keys := strings.Join(patternKeys, "|")
+		p.jsonPattern = regexp.MustCompile(`(?i)("(?:` + keys + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]*)`)
+		p.formPattern = regexp.MustCompile(`(?i)((?:^|[&?;\s])(?:` + keys + `)=)[^&;]*`)
+	}
+
+	return p, nil
+}
+
+// SetRequestLog replaces the redaction rules and the body limits of the request logs.
+// The details are logged to the default logger at debug level, or to the sink if it is set.
+func SetRequestLog(c RequestLogConfig) error {
+	requestLogLock.Lock()
+	defer requestLogLock.Unlock()
+
+	p, err := newRequestLogPolicy(c)
+	if err != nil {
+		return err
+	}
+
+	current := getRequestLog()
+	p.sink, p.closer = current.sink, current.closer
+	if c.Sink != current.conf.Sink {
+		p.sink, p.closer = nil, nil
+		if c.Sink.File != "" {
+			p.sink, p.closer = logger.NewFileLogger(c.Sink)
+		}
+	}
+
+	_requestLog.Store(p)
+	if current.closer != nil && current.closer != p.closer {
+		_ = current.closer.Close()
+	}
+
+	return nil
+}
+
+func getRequestLog() *requestLogPolicy {
+	return _requestLog.Load().(*requestLogPolicy)
+}
+
+func mediaType(contentType string) string {
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	return t
+}
+
+func (p *requestLogPolicy) captured(contentType string) bool {
+	if p.conf.MaxBodySize == 0 {
+		return false
+	}
+
+	t := mediaType(contentType)
+	for _, allowed := range p.contentTypes {
+		if t == allowed || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(t, allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestBody captures the head of the body without reading the rest, which is left to the handler
+func (p *requestLogPolicy) requestBody(r *http.Request) string {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return ""
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !p.captured(contentType) {
+		return omittedBody(contentType, r.ContentLength)
+	}
+
+	head, _ := ioutil.ReadAll(io.LimitReader(r.Body, int64(p.conf.MaxBodySize)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+
+	return p.body(head, contentType, r.ContentLength)
+}
+
+// body redacts and truncates a captured body, total is its size or -1 if unknown
+func (p *requestLogPolicy) body(b []byte, contentType string, total int64) string {
+	truncated := len(b) > p.conf.MaxBodySize
+	if truncated {
+		b = b[:p.conf.MaxBodySize]
+	}
+
+	s := p.redactBody(b, contentType, truncated)
+	if !truncated {
+		return s
+	}
+
+	if total > 0 {
+		return fmt.Sprintf("%s...[truncated, %d bytes in total]", s, total)
+	}
+
+	return s + "...[truncated]"
+}
+
+func omittedBody(contentType string, size int64) string {
+	if contentType == "" {
+		contentType = "unknown content type"
+	}
+
+	if size > 0 {
+		return fmt.Sprintf("[%s body of %d bytes omitted]", mediaType(contentType), size)
+	}
+
+	return fmt.Sprintf("[%s body omitted]", mediaType(contentType))
+}
+
+func (p *requestLogPolicy) redactBody(b []byte, contentType string, truncated bool) string {
+	t := mediaType(contentType)
+	if !truncated && strings.Contains(t, "json") {
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		decoder.UseNumber()
+
+		var v interface{}
+		if err := decoder.Decode(&v); err == nil {
+			redacted, _ := json.Marshal(p.redactJSON(v, nil))
+			return string(redacted)
+		}
+	}
+
+	if t == "application/x-www-form-urlencoded" && !truncated {
+		if values, err := url.ParseQuery(string(b)); err == nil {
+			return p.redactValues(values).Encode()
+		}
+	}
+
+	return p.redactText(string(b))
+}
+
+func (p *requestLogPolicy) redactText(s string) string {
+	if p.jsonPattern == nil {
+		return s
+	}
+
+	s = p.jsonPattern.ReplaceAllString(s, `${1}"`+redactedValue+`"`)
+	return p.formPattern.ReplaceAllString(s, "${1}"+redactedValue)
+}
+
+func (p *requestLogPolicy) redactJSON(v interface{}, path []string) interface{} {
+	if p.matchPath(path) {
+		return redactedValue
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, e := range value {
+			if p.keys[strings.ToLower(k)] {
+				value[k] = redactedValue
+				continue
+			}
+			value[k] = p.redactJSON(e, appendPath(path, k))
+		}
+	case []interface{}:
+		for i, e := range value {
+			value[i] = p.redactJSON(e, appendPath(path, strconv.Itoa(i)))
+		}
+	}
+
+	return v
+}
+
+func appendPath(path []string, segment string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), segment)
+}
+
+func (p *requestLogPolicy) matchPath(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	for _, pattern := range p.paths {
+		if len(pattern) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range pattern {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *requestLogPolicy) redactValues(values url.Values) url.Values {
+	redacted := make(url.Values, len(values))
+	for k, v := range values {
+		if p.keys[strings.ToLower(k)] {
+			v = []string{redactedValue}
+		}
+		redacted[k] = v
+	}
+
+	return redacted
+}
+
+// params returns the form and query parameters in JSON
+func (p *requestLogPolicy) params(r *http.Request) string {
+	params, _ := json.Marshal(p.redactValues(r.Form))
+	return string(params)
+}
+
+func (p *requestLogPolicy) redactURI(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return u.EscapedPath() + "?" + p.redactText(u.RawQuery)
+	}
+
+	return u.EscapedPath() + "?" + p.redactValues(values).Encode()
+}
+
+func (p *requestLogPolicy) requestHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for k, v := range h {
+		if p.headers[http.CanonicalHeaderKey(k)] {
+			headers[k] = redactedValue
+			continue
+		}
+		headers[k] = strings.Join(v, ", ")
+	}
+
+	return headers
+}
+
+// response returns the captured response body of w
+func (p *requestLogPolicy) response(w *bodyLogWriter) string {
+	if w.Size() <= 0 {
+		return ""
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	if !p.captured(contentType) {
+		return omittedBody(contentType, int64(w.Size()))
+	}
+
+	return p.body(w.body.Bytes(), contentType, int64(w.Size()))
+}
+
+// write logs lg to the sink, or the default logger at debug level
+func (p *requestLogPolicy) write(c *gin.Context, lg *httpReqResLog) {
+	if p.sink == nil {
+		logBytes, _ := json.Marshal(lg)
+		logger.DebugfWithTrace(c, "request details: %s", string(logBytes))
+		return
+	}
+
+	fields := append(logger.Fields(c),
+		zap.String("operator", lg.Operator),
+		zap.String("uri", lg.URI),
+		zap.String("method", lg.Method),
+		zap.String("params", lg.Params),
+		zap.String("client", lg.Client),
+		zap.Int("status_code", lg.StatusCode),
+		zap.Any("headers", lg.Headers),
+	)
+	if lg.Response != "" {
+		fields = append(fields, zap.String("response", lg.Response))
+	}
+
+	p.sink.Info("request details", fields...)
+}
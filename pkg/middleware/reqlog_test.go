@@ -0,0 +1,138 @@
+/*
+@Date: 2026/10/19 21:40
+@Author: yvanz
+@File : reqlog_test
+*/
+
+package middleware
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+func TestRequestLogRedact(t *testing.T) {
+	p, err := newRequestLogPolicy(RequestLogConfig{
+		RedactKeys:    []string{"password", "token"},
+		RedactPaths:   []string{"card.number", "users.*.pin"},
+		RedactHeaders: []string{"authorization"},
+		ContentTypes:  []string{"application/json", "application/x-www-form-urlencoded", "text/"},
+		MaxBodySize:   100,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, tc := range []struct {
+		body        string
+		contentType string
+		want        string
+	}{
+		{`{"name":"a","Password":"p","card":{"number":"1","cvv":2},"users":[{"pin":3,"id":1}]}`, "application/json; charset=utf-8",
+			`{"Password":"******","card":{"cvv":2,"number":"******"},"name":"a","users":[{"id":1,"pin":"******"}]}`},
+		{`{"user":{"token":"abc","id":12345678901234567890}}`, "application/json",
+			`{"user":{"id":12345678901234567890,"token":"******"}}`},
+		{`{"padding":"` + strings.Repeat("0", 55) + `","token": "abc", "number":"1234567890"}`, "application/json",
+			`{"padding":"` + strings.Repeat("0", 55) + `","token": "******", "number":"******"...[truncated]`},
+		{"name=a&password=p", "application/x-www-form-urlencoded", "name=a&password=%2A%2A%2A%2A%2A%2A"},
+		{"login with token=abc&x=1", "text/plain", "login with token=******&x=1"},
+		{"\x89PNG", "image/png", "[image/png body omitted]"},
+		{"--x", "multipart/form-data; boundary=x", "[multipart/form-data body omitted]"},
+	} {
+		var got string
+		if p.captured(tc.contentType) {
+			got = p.body([]byte(tc.body), tc.contentType, -1)
+		} else {
+			got = omittedBody(tc.contentType, -1)
+		}
+
+		if got != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.body, got, tc.want)
+		}
+	}
+
+	u, _ := url.Parse("/login?user=a&token=t")
+	if uri := p.redactURI(u); uri != "/login?token=%2A%2A%2A%2A%2A%2A&user=a" {
+		t.Errorf("got uri %s", uri)
+	}
+
+	headers := p.requestHeaders(http.Header{"Authorization": {"Bearer t"}, "Accept": {"a", "b"}})
+	if headers["Authorization"] != redactedValue || headers["Accept"] != "a, b" {
+		t.Errorf("got headers %v", headers)
+	}
+
+	if _, err = newRequestLogPolicy(RequestLogConfig{RedactPaths: []string{"a..b"}}); err == nil {
+		t.Error("invalid path is accepted")
+	}
+}
+
+func TestGinInterceptorSink(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "request.log")
+	old := getRequestLog().conf
+	if err := SetRequestLog(RequestLogConfig{
+		RedactKeys:    []string{"password"},
+		RedactHeaders: []string{"Authorization"},
+		ContentTypes:  []string{"application/json"},
+		Sink:          logger.FileConfig{File: file},
+		MaxBodySize:   16,
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		_ = SetRequestLog(old)
+	}()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(GinInterceptor(true))
+	g.POST("/login", func(c *gin.Context) {
+		// the handler reads the whole body whatever is captured
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"size": len(body), "message": strings.Repeat("x", 32)})
+	})
+
+	body := `{"password":"p","data":"` + strings.Repeat("d", 100) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer t")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), `"size":126`) {
+		t.Fatalf("the handler got %s", w.Body.String())
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var entry struct {
+		Headers  map[string]string `json:"headers"`
+		Msg      string            `json:"msg"`
+		Params   string            `json:"params"`
+		Response string            `json:"response"`
+	}
+	if err = json.Unmarshal(content, &entry); err != nil {
+		t.Fatalf("%s: %s", err.Error(), content)
+	}
+
+	if entry.Msg != "request details" || entry.Headers["Authorization"] != redactedValue {
+		t.Errorf("unexpected entry %s", content)
+	}
+	if entry.Params != `{"password":"******",...[truncated, 126 bytes in total]` {
+		t.Errorf("got params %s", entry.Params)
+	}
+	if entry.Response != `{"message":"xxxx...[truncated, 57 bytes in total]` {
+		t.Errorf("got response %s", entry.Response)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+@Date: 2026/10/19 19:50
+@Author: yvanz
+@File : requestid
+*/
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID accepts the X-Request-ID of the request or generates one, and echoes it in the response.
+// The ID is kept in the gin.Context and the context of the request, see gadget.RequestID.
+// The route of the request is added to the log fields of the context, see logger.WithContext.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(gadget.RequestIDHeader)
+		if !validRequestID(id) {
+			id = gadget.UUID()
+		}
+
+		c.Set(gadget.RequestIDCtxKey, id)
+		c.Request = c.Request.WithContext(gadget.WithRequestID(c.Request.Context(), id))
+		c.Header(gadget.RequestIDHeader, id)
+		if route := c.FullPath(); route != "" {
+			logger.WithContext(c, "route", c.Request.Method+" "+route)
+		}
+		c.Next()
+	}
+}
+
+// validRequestID rejects the IDs which could break the log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' || id[i] == '"' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetRequestID returns the request ID set by RequestID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(gadget.RequestIDCtxKey)
+}
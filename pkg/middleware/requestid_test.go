@@ -0,0 +1,60 @@
+/*
+@Date: 2026/10/19 20:00
+@Author: yvanz
+@File : requestid_test
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
+	"github.com/yvanz/gin-tmpl/pkg/httputil"
+)
+
+func TestRequestID(t *testing.T) {
+	// the downstream service sees the request ID forwarded by httputil.Send
+	var forwarded string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Get(gadget.RequestIDHeader)
+	}))
+	defer downstream.Close()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(RequestID())
+	g.GET("/call", func(c *gin.Context) {
+		if _, err := httputil.Get(downstream.URL, httputil.SendContext(c.Request.Context())); err != nil {
+			t.Error(err.Error())
+		}
+		c.String(http.StatusOK, gadget.RequestID(c))
+	})
+
+	for _, tc := range []struct {
+		header   string
+		accepted bool
+	}{
+		{"req-123", true},
+		{"", false},
+		{"bad id\n", false},
+		{strings.Repeat("a", maxRequestIDLength+1), false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/call", nil)
+		req.Header.Set(gadget.RequestIDHeader, tc.header)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		id := w.Header().Get(gadget.RequestIDHeader)
+		if tc.accepted != (id == tc.header) || id == "" {
+			t.Errorf("%q: unexpected request ID %q", tc.header, id)
+		}
+		if w.Body.String() != id || forwarded != id {
+			t.Errorf("%q: want %q in the context and downstream, got %q and %q", tc.header, id, w.Body.String(), forwarded)
+		}
+	}
+}
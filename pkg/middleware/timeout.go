@@ -0,0 +1,97 @@
+/*
+@Date: 2026/10/19 20:50
+@Author: yvanz
+@File : timeout
+*/
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	_timeouts atomic.Value
+
+	registerTimeoutMetricsOnce sync.Once
+
+	httpRequestTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_request_timeouts_total",
+		Help: "Total number of HTTP requests exceeding their timeout budget.",
+	}, []string{"route", "method"})
+)
+
+// SetTimeouts replaces the budgets of Timeout by name
+func SetTimeouts(budgets map[string]time.Duration) error {
+	timeouts := make(map[string]time.Duration, len(budgets))
+	for name, d := range budgets {
+		if d < 0 {
+			return fmt.Errorf("timeout %s must not be negative", name)
+		}
+		timeouts[name] = d
+	}
+
+	_timeouts.Store(timeouts)
+	return nil
+}
+
+// Timeout puts the budget named name in the timeout config as the deadline of the request context,
+// the requests have no deadline if it is not configured or 0.
+// The deadline is cooperative: the handler runs to the end, and the database, redis and HTTP calls
+// fail once it expires if they are given the context, see gadget.RequestContext.
+// onTimeout responds to the timed-out requests which have not been responded by the handler.
+// A 504 response is sent if it is nil. The timed-out requests are counted by http_request_timeouts_total.
+func Timeout(name string, onTimeout func(c *gin.Context, err error)) gin.HandlerFunc {
+	registerTimeoutMetricsOnce.Do(func() {
+		prometheus.MustRegister(httpRequestTimeouts)
+	})
+
+	if onTimeout == nil {
+		onTimeout = func(c *gin.Context, err error) {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"message": err.Error()})
+		}
+	}
+
+	return func(c *gin.Context) {
+		timeouts, _ := _timeouts.Load().(map[string]time.Duration)
+		d := timeouts[name]
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if ctx.Err() != context.DeadlineExceeded {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		httpRequestTimeouts.WithLabelValues(route, methodLabel(c.Request.Method)).Inc()
+
+		if !c.Writer.Written() {
+			onTimeout(c, ctx.Err())
+			c.Abort()
+		}
+	}
+}
+
+// TimedOut reports whether the budget of Timeout is exceeded, the handlers could respond a timeout by it
+func TimedOut(c *gin.Context) bool {
+	return c.Request.Context().Err() == context.DeadlineExceeded
+}
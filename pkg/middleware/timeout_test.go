@@ -0,0 +1,84 @@
+/*
+@Date: 2026/10/19 21:00
+@Author: yvanz
+@File : timeout_test
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
+	"github.com/yvanz/gin-tmpl/pkg/httputil"
+)
+
+func TestTimeout(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer downstream.Close()
+
+	if err := SetTimeouts(map[string]time.Duration{"short": 50 * time.Millisecond}); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = SetTimeouts(nil) }()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	for _, name := range []string{"short", "none"} {
+		group := g.Group("/"+name, Timeout(name, nil))
+		group.GET("/fast", func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+		call := func(c *gin.Context) {
+			// the gin.Context carries the deadline of the request to the downstream call
+			if _, err := httputil.Get(downstream.URL, httputil.SendContext(c)); err != nil {
+				return
+			}
+			c.String(http.StatusOK, "ok")
+		}
+		group.GET("/call", call)
+		group.Handle("PURGE", "/call", call)
+		group.GET("/deadline", func(c *gin.Context) {
+			_, ok := gadget.RequestContext(c).Deadline()
+			c.JSON(http.StatusOK, ok)
+		})
+	}
+
+	timeouts := testutil.ToFloat64(httpRequestTimeouts.WithLabelValues("/short/call", http.MethodGet))
+	for _, tc := range []struct {
+		path string
+		body string
+		code int
+	}{
+		{"/short/fast", "ok", http.StatusOK},
+		{"/short/deadline", "true", http.StatusOK},
+		{"/short/call", `{"message":"context deadline exceeded"}`, http.StatusGatewayTimeout},
+		{"/none/deadline", "false", http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.code || w.Body.String() != tc.body {
+			t.Errorf("%s: got %d %s", tc.path, w.Code, w.Body.String())
+		}
+	}
+
+	if n := testutil.ToFloat64(httpRequestTimeouts.WithLabelValues("/short/call", http.MethodGet)); n != timeouts+1 {
+		t.Errorf("got %v timeouts", n-timeouts)
+	}
+
+	others := testutil.ToFloat64(httpRequestTimeouts.WithLabelValues("/short/call", otherMethod))
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PURGE", "/short/call", nil))
+	if n := testutil.ToFloat64(httpRequestTimeouts.WithLabelValues("/short/call", otherMethod)); n != others+1 {
+		t.Errorf("want the non-standard method labelled %s, got %v more", otherMethod, n-others)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+@Date: 2026/10/19 18:20
+@Author: yvanz
+@File : config
+*/
+
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	TokenBucket   = "token_bucket"
+	SlidingWindow = "sliding_window"
+
+	KeyIP     = "ip"
+	KeyUser   = "user"
+	KeyAPIKey = "api_key"
+	KeyRoute  = "route"
+)
+
+// Config of a limiter, the zero values are replaced by the defaults in comments
+type Config struct {
+	// token_bucket (default) or sliding_window
+	Algorithm string `yaml:"algorithm" json:"algorithm,omitempty"`
+	// KeyBy is what the requests are counted by: ip (default), user, api_key and route, e.g. [route, user].
+	// The requests without a user or an API key are counted by ip.
+	KeyBy []string `yaml:"key_by" json:"key_by,omitempty"`
+	// APIKeyHeader is the header of the API key, X-API-Key by default
+	APIKeyHeader string `yaml:"api_key_header" json:"api_key_header,omitempty"`
+	// Limit is the number of requests allowed in every period
+	Limit int `yaml:"limit" json:"limit,omitempty"`
+	// Burst is the capacity of the token bucket, Limit by default
+	Burst int `yaml:"burst" json:"burst,omitempty"`
+	// Period is 1s by default
+	Period time.Duration `yaml:"period" json:"period,omitempty"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Algorithm == "" {
+		c.Algorithm = TokenBucket
+	}
+	if len(c.KeyBy) == 0 {
+		c.KeyBy = []string{KeyIP}
+	}
+	if c.APIKeyHeader == "" {
+		c.APIKeyHeader = "X-API-Key"
+	}
+	if c.Burst == 0 {
+		c.Burst = c.Limit
+	}
+	if c.Period == 0 {
+		c.Period = time.Second
+	}
+
+	return c
+}
+
+// Validate returns all problems of the config
+func (c Config) Validate() (errs []error) {
+	switch c.Algorithm {
+	case "", TokenBucket, SlidingWindow:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported algorithm %q, only support %s/%s", c.Algorithm, TokenBucket, SlidingWindow))
+	}
+
+	for _, k := range c.KeyBy {
+		switch k {
+		case KeyIP, KeyUser, KeyAPIKey, KeyRoute:
+		default:
+			errs = append(errs, fmt.Errorf("unsupported key_by %q, only support %s/%s/%s/%s", k, KeyIP, KeyUser, KeyAPIKey, KeyRoute))
+		}
+	}
+
+	if c.Limit <= 0 {
+		errs = append(errs, fmt.Errorf("limit must be positive"))
+	}
+	if c.Burst < 0 || c.Period < 0 {
+		errs = append(errs, fmt.Errorf("burst and period must not be negative"))
+	}
+	if c.Period > 0 && c.Period < time.Millisecond {
+		errs = append(errs, fmt.Errorf("period must not be less than 1ms"))
+	}
+
+	return errs
+}
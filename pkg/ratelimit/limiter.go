@@ -0,0 +1,153 @@
+/*
+@Date: 2026/10/19 18:50
+@Author: yvanz
+@File : limiter
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/yvanz/gin-tmpl/pkg/logger"
+	"github.com/yvanz/gin-tmpl/pkg/rediscache"
+)
+
+const (
+	redisTimeout = 200 * time.Millisecond
+	// redis is not tried again in this interval after it fails
+	redisRetryInterval = 5 * time.Second
+)
+
+var (
+	ErrLimited = errors.New("too many requests")
+
+	_limiters atomic.Value
+	// unix nano before which the in-process limiters are used
+	_redisRetryAt int64
+)
+
+// Result of a request
+type Result struct {
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+	Allowed    bool
+}
+
+// Limiter counts the requests in redis by the default client of rediscache so that the limits are shared
+// by all instances. It falls back to an in-process limiter if redis is not initialized or unavailable.
+type Limiter struct {
+	local *localLimiter
+	name  string
+	conf  Config
+}
+
+// New returns a Limiter of c, name is a part of the redis keys
+func New(name string, c Config) (*Limiter, error) {
+	if errs := c.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("rate limit %s: %s", name, errs[0].Error())
+	}
+
+	c = c.withDefaults()
+	return &Limiter{name: name, conf: c, local: newLocalLimiter(c)}, nil
+}
+
+// Config returns the config with the defaults
+func (l *Limiter) Config() Config {
+	return l.conf
+}
+
+func (l *Limiter) capacity() int {
+	if l.conf.Algorithm == TokenBucket {
+		return l.conf.Burst
+	}
+
+	return l.conf.Limit
+}
+
+// Allow counts a request of key and reports whether it is allowed
+func (l *Limiter) Allow(ctx context.Context, key string) Result {
+	return l.allow(ctx, rediscache.GetCli(), key)
+}
+
+func (l *Limiter) allow(ctx context.Context, rdb *redis.Client, key string) Result {
+	if rdb != nil && time.Now().UnixNano() >= atomic.LoadInt64(&_redisRetryAt) {
+		// the script is not cancelled with the request, so that the clients dropping the connections
+		// could not make all limiters fall back to the in-process ones
+		res, err := l.allowRedis(detachedContext{ctx}, rdb, key)
+		if err == nil {
+			if atomic.SwapInt64(&_redisRetryAt, 0) != 0 {
+				logger.Info("redis is available again, rate limits are shared by all instances")
+			}
+			return res
+		}
+		if ctx.Err() != nil {
+			return l.local.allow(l.conf, key, time.Now())
+		}
+
+		if atomic.SwapInt64(&_redisRetryAt, time.Now().Add(redisRetryInterval).UnixNano()) == 0 {
+			logger.Warnf("rate limits fall back to the in-process limiters: %s", err.Error())
+		}
+	}
+
+	return l.local.allow(l.conf, key, time.Now())
+}
+
+// Set replaces the limiters by the configs keyed by name, the limiters of unchanged configs are kept
+func Set(configs map[string]Config) error {
+	current, _ := _limiters.Load().(map[string]*Limiter)
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	limiters := make(map[string]*Limiter, len(configs))
+	for _, name := range names {
+		l, err := New(name, configs[name])
+		if err != nil {
+			return err
+		}
+
+		if old, ok := current[name]; ok && reflect.DeepEqual(old.conf, l.conf) {
+			l = old
+		}
+		limiters[name] = l
+	}
+
+	_limiters.Store(limiters)
+	return nil
+}
+
+// Get returns the limiter named name by Set, or nil
+func Get(name string) *Limiter {
+	limiters, _ := _limiters.Load().(map[string]*Limiter)
+	return limiters[name]
+}
+
+// detachedContext keeps the values of the context, such as the trace, without its deadline and cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
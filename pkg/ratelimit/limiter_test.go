@@ -0,0 +1,118 @@
+/*
+@Date: 2026/10/19 19:10
+@Author: yvanz
+@File : limiter_test
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestLocalTokenBucket(t *testing.T) {
+	c := Config{Limit: 2, Burst: 3, Period: time.Second}.withDefaults()
+	l := newLocalLimiter(c)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if res := l.allow(c, "k", now); !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: unexpected %+v", i, res)
+		}
+	}
+
+	res := l.allow(c, "k", now)
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Errorf("want limited with retry after 500ms, got %+v", res)
+	}
+	if res = l.allow(c, "other", now); !res.Allowed {
+		t.Error("keys should be limited separately")
+	}
+
+	if res = l.allow(c, "k", now.Add(500*time.Millisecond)); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("a token should be refilled, got %+v", res)
+	}
+}
+
+func TestLocalSlidingWindow(t *testing.T) {
+	c := Config{Algorithm: SlidingWindow, Limit: 2, Period: time.Second}.withDefaults()
+	l := newLocalLimiter(c)
+	now := time.Now()
+
+	l.allow(c, "k", now)
+	l.allow(c, "k", now.Add(400*time.Millisecond))
+
+	res := l.allow(c, "k", now.Add(600*time.Millisecond))
+	if res.Allowed || res.RetryAfter != 400*time.Millisecond {
+		t.Errorf("want limited with retry after 400ms, got %+v", res)
+	}
+
+	if res = l.allow(c, "k", now.Add(time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("the first request should be out of the window, got %+v", res)
+	}
+}
+
+func TestSet(t *testing.T) {
+	if err := Set(map[string]Config{"api": {Limit: 0}}); err == nil {
+		t.Error("invalid limit should be rejected")
+	}
+
+	if err := Set(map[string]Config{"api": {Limit: 10}, "login": {Limit: 1}}); err != nil {
+		t.Fatal(err.Error())
+	}
+	api := Get("api")
+
+	if err := Set(map[string]Config{"api": {Limit: 10}}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if Get("api") != api {
+		t.Error("the limiter of an unchanged config should be kept")
+	}
+	if Get("login") != nil {
+		t.Error("the removed limiter should be dropped")
+	}
+}
+
+func TestAllowCancelledRequest(t *testing.T) {
+	t.Cleanup(func() { atomic.StoreInt64(&_redisRetryAt, 0) })
+
+	var dialErr error
+	rdb := redis.NewClient(&redis.Options{
+		Addr:       "redis:6379",
+		MaxRetries: -1,
+		Dialer: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			dialErr = ctx.Err()
+			return nil, errors.New("redis is down")
+		},
+	})
+	defer rdb.Close()
+
+	l, err := New("api", Config{Limit: 10})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res := l.allow(ctx, rdb, "k"); !res.Allowed {
+		t.Errorf("want allowed by the in-process limiter, got %+v", res)
+	}
+	if dialErr != nil {
+		t.Errorf("the script should not be cancelled with the request: %s", dialErr.Error())
+	}
+	if atomic.LoadInt64(&_redisRetryAt) != 0 {
+		t.Error("a cancelled request should not make the limiters fall back")
+	}
+
+	l.allow(context.Background(), rdb, "k")
+	if atomic.LoadInt64(&_redisRetryAt) == 0 {
+		t.Error("the limiters should fall back when redis is down")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+@Date: 2026/10/19 18:40
+@Author: yvanz
+@File : local
+*/
+
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// localLimiter is the in-process fallback of the redis scripts, the limits are counted by every instance
+type localLimiter struct {
+	states    map[string]*localState
+	lastSweep time.Time
+	// idle is how long a state takes to be the same as a missing one
+	idle time.Duration
+	lock sync.Mutex
+}
+
+type localState struct {
+	last   time.Time
+	hits   []time.Time
+	tokens float64
+}
+
+func newLocalLimiter(c Config) *localLimiter {
+	idle := c.Period
+	if c.Algorithm == TokenBucket {
+		idle = time.Duration(float64(c.Period) * float64(c.Burst) / float64(c.Limit))
+	}
+
+	return &localLimiter{states: make(map[string]*localState), idle: idle, lastSweep: time.Now()}
+}
+
+func (l *localLimiter) allow(c Config, key string, now time.Time) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, s := range l.states {
+			if now.Sub(s.last) > l.idle {
+				delete(l.states, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	s, ok := l.states[key]
+	if !ok {
+		s = &localState{tokens: float64(c.Burst), last: now}
+		l.states[key] = s
+	}
+
+	if c.Algorithm == SlidingWindow {
+		return s.slidingWindow(c, now)
+	}
+
+	return s.tokenBucket(c, now)
+}
+
+func (s *localState) tokenBucket(c Config, now time.Time) Result {
+	rate := float64(c.Limit) / float64(c.Period)
+	if elapsed := now.Sub(s.last); elapsed > 0 {
+		s.tokens = math.Min(float64(c.Burst), s.tokens+float64(elapsed)*rate)
+	}
+	s.last = now
+
+	res := Result{Limit: c.Burst}
+	if s.tokens >= 1 {
+		s.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - s.tokens) / rate))
+	}
+
+	res.Remaining = int(s.tokens)
+	res.ResetAfter = time.Duration(math.Ceil((float64(c.Burst) - s.tokens) / rate))
+	return res
+}
+
+func (s *localState) slidingWindow(c Config, now time.Time) Result {
+	i := 0
+	for i < len(s.hits) && now.Sub(s.hits[i]) >= c.Period {
+		i++
+	}
+	s.hits = s.hits[i:]
+	s.last = now
+
+	res := Result{Limit: c.Limit}
+	if len(s.hits) < c.Limit {
+		s.hits = append(s.hits, now)
+		res.Allowed = true
+	}
+
+	res.Remaining = c.Limit - len(s.hits)
+	res.ResetAfter = s.hits[0].Add(c.Period).Sub(now)
+	if !res.Allowed {
+		res.RetryAfter = res.ResetAfter
+	}
+
+	return res
+}
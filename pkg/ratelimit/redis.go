@@ -0,0 +1,120 @@
+/*
+@Date: 2026/10/19 18:30
+@Author: yvanz
+@File : redis
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// the scripts take the time from redis, so that the instances with clock skew share the same limits.
+// redis.replicate_commands is required by redis < 5 to write after TIME.
+
+// tokenBucketScript ARGV: limit, period in ms, burst.
+// Returns allowed, remaining, retry after in ms and reset after in ms.
+var tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local limit, period, burst = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local rate = limit / period
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens, ts = tonumber(state[1]), tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens, ts = burst, now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return {allowed, math.floor(tokens), retry, math.ceil((burst - tokens) / rate)}
+`)
+
+// slidingWindowScript logs the requests in a sorted set, so it suits small limits.
+// ARGV: limit, window in ms, a unique member. Returns the same as tokenBucketScript.
+var slidingWindowScript = redis.NewScript(`
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local limit, window = tonumber(ARGV[1]), tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, limit - count, retry, reset}
+`)
+
+func (l *Limiter) allowRedis(ctx context.Context, rdb *redis.Client, key string) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	period := l.conf.Period.Milliseconds()
+	keys := []string{"ratelimit:" + l.name + ":" + key}
+
+	var v interface{}
+	var err error
+	if l.conf.Algorithm == SlidingWindow {
+		member := strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.FormatInt(rand.Int63(), 36) //nolint:gosec
+		v, err = slidingWindowScript.Run(ctx, rdb, keys, l.conf.Limit, period, member).Result()
+	} else {
+		v, err = tokenBucketScript.Run(ctx, rdb, keys, l.conf.Limit, period, l.conf.Burst).Result()
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := v.([]interface{})
+	if !ok || len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected result of the rate limit script: %v", v)
+	}
+
+	n := make([]int64, len(values))
+	for i, value := range values {
+		if n[i], ok = value.(int64); !ok {
+			return Result{}, fmt.Errorf("unexpected result of the rate limit script: %v", v)
+		}
+	}
+
+	return Result{
+		Allowed:    n[0] == 1,
+		Limit:      l.capacity(),
+		Remaining:  int(n[1]),
+		RetryAfter: time.Duration(n[2]) * time.Millisecond,
+		ResetAfter: time.Duration(n[3]) * time.Millisecond,
+	}, nil
+}
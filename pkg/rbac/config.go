@@ -0,0 +1,30 @@
+/*
+@Date: 2026/10/19 16:40
+@Author: yvanz
+@File : config
+*/
+
+package rbac
+
+import "fmt"
+
+type Config struct {
+	File       string `yaml:"file" env:"RBACFile" env-description:"yaml file of the policies" json:"file,omitempty" reload:"restart"`
+	Table      string `yaml:"table" env:"RBACTable" env-description:"mysql table of the policy rules, see rbac.Rule" json:"table,omitempty" reload:"restart"`
+	RolesClaim string `yaml:"roles_claim" env:"RBACRolesClaim" env-default:"roles" env-description:"JWT claim of the roles granted by the identity provider" json:"roles_claim,omitempty" reload:"restart"`
+	DryRun     bool   `yaml:"dry_run" env:"RBACDryRun" env-description:"log the denials without enforcing them" json:"dry_run,omitempty"`
+}
+
+// Enabled reports whether a policy source is configured
+func (c Config) Enabled() bool {
+	return c.File != "" || c.Table != ""
+}
+
+// Validate returns all problems of the config
+func (c Config) Validate() (errs []error) {
+	if c.File != "" && c.Table != "" {
+		errs = append(errs, fmt.Errorf("file and table must not be set together"))
+	}
+
+	return errs
+}
@@ -0,0 +1,208 @@
+/*
+@Date: 2026/10/19 16:50
+@Author: yvanz
+@File : policy
+*/
+
+package rbac
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Any matches all methods in a resource, all permissions of a role, or all subjects of a binding
+const Any = "*"
+
+// Policy grants permissions to roles and roles to subjects.
+// A permission is a list of resources "METHOD /path", the method may be * and the path is matched against
+// the route path (gin FullPath, e.g. /api/v1/demo/:id) by path.Match, a trailing /** matches all sub paths.
+type Policy struct {
+	Permissions map[string][]string `yaml:"permissions" json:"permissions"`
+	Roles       map[string][]string `yaml:"roles" json:"roles"`
+	Subjects    map[string][]string `yaml:"subjects" json:"subjects"`
+}
+
+const (
+	// RuleSubject grants the role Value to the subject Name
+	RuleSubject = "subject"
+	// RuleRole grants the permission Value to the role Name
+	RuleRole = "role"
+	// RulePermission adds the resource Value to the permission Name
+	RulePermission = "permission"
+)
+
+// Rule is a row of the policy table, create the table by gormdb.DB.Migration or Table(name).AutoMigrate
+type Rule struct {
+	ID    int64  `json:"Id" gorm:"column:id;primaryKey"`
+	Kind  string `json:"Kind" gorm:"column:kind;type:varchar(16);index"`
+	Name  string `json:"Name" gorm:"column:name;type:varchar(128)"`
+	Value string `json:"Value" gorm:"column:value;type:varchar(255)"`
+}
+
+func (Rule) TableName() string {
+	return "rbac_rules"
+}
+
+func policyFromRules(rules []Rule) (Policy, error) {
+	p := Policy{
+		Permissions: make(map[string][]string),
+		Roles:       make(map[string][]string),
+		Subjects:    make(map[string][]string),
+	}
+
+	for _, r := range rules {
+		switch r.Kind {
+		case RuleSubject:
+			p.Subjects[r.Name] = append(p.Subjects[r.Name], r.Value)
+		case RuleRole:
+			p.Roles[r.Name] = append(p.Roles[r.Name], r.Value)
+		case RulePermission:
+			p.Permissions[r.Name] = append(p.Permissions[r.Name], r.Value)
+		default:
+			return p, fmt.Errorf("rule %d: unknown kind %q", r.ID, r.Kind)
+		}
+	}
+
+	return p, nil
+}
+
+type resource struct {
+	method  string
+	pattern string
+	// pattern matches the leading segments of the path if it ends with /**
+	prefix bool
+}
+
+func parseResource(s string) (resource, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+		return resource{}, fmt.Errorf("invalid resource %q, it should be like \"GET /api/v1/demo/:id\"", s)
+	}
+
+	r := resource{method: strings.ToUpper(fields[0]), pattern: fields[1]}
+	if strings.HasSuffix(r.pattern, "/**") {
+		r.pattern, r.prefix = strings.TrimSuffix(r.pattern, "/**"), true
+	}
+	if _, err := path.Match(r.pattern, ""); err != nil {
+		return resource{}, fmt.Errorf("invalid resource %q: %s", s, err.Error())
+	}
+
+	return r, nil
+}
+
+func (r resource) match(method, fullPath string) bool {
+	if r.method != Any && r.method != method {
+		return false
+	}
+
+	if r.prefix {
+		n := strings.Count(r.pattern, "/")
+		segments := strings.SplitN(fullPath, "/", n+2)
+		if len(segments) < n+1 {
+			return false
+		}
+		fullPath = strings.Join(segments[:n+1], "/")
+	}
+
+	ok, _ := path.Match(r.pattern, fullPath)
+	return ok
+}
+
+// compiled is the policy with the references checked and the resources parsed
+type compiled struct {
+	subjects    map[string][]string
+	roles       map[string]map[string]bool
+	permissions map[string][]resource
+}
+
+func compile(p Policy) (*compiled, error) {
+	c := &compiled{
+		subjects:    p.Subjects,
+		roles:       make(map[string]map[string]bool, len(p.Roles)),
+		permissions: make(map[string][]resource, len(p.Permissions)),
+	}
+
+	var problems []string
+	for name, resources := range p.Permissions {
+		for _, s := range resources {
+			r, err := parseResource(s)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("permission %s: %s", name, err.Error()))
+				continue
+			}
+			c.permissions[name] = append(c.permissions[name], r)
+		}
+	}
+
+	for role, permissions := range p.Roles {
+		c.roles[role] = make(map[string]bool, len(permissions))
+		for _, perm := range permissions {
+			if _, ok := p.Permissions[perm]; !ok && perm != Any {
+				problems = append(problems, fmt.Sprintf("role %s: unknown permission %s", role, perm))
+			}
+			c.roles[role][perm] = true
+		}
+	}
+
+	for subject, roles := range p.Subjects {
+		for _, role := range roles {
+			if _, ok := p.Roles[role]; !ok {
+				problems = append(problems, fmt.Sprintf("subject %s: unknown role %s", subject, role))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid policy: %s", strings.Join(problems, "; "))
+	}
+
+	return c, nil
+}
+
+// rolesOf returns the roles bound to subject, to all subjects, and the extra roles granted by the token
+func (c *compiled) rolesOf(subject string, extra []string) []string {
+	roles := append([]string{}, extra...)
+	if subject != "" {
+		roles = append(roles, c.subjects[subject]...)
+		roles = append(roles, c.subjects[Any]...)
+	}
+
+	return roles
+}
+
+func (c *compiled) hasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		if perms := c.roles[role]; perms[permission] || perms[Any] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *compiled) allows(roles []string, method, fullPath string) bool {
+	for _, role := range roles {
+		perms, ok := c.roles[role]
+		if !ok {
+			continue
+		}
+
+		for name, resources := range c.permissions {
+			if !perms[name] && !perms[Any] {
+				continue
+			}
+
+			for _, r := range resources {
+				if r.match(method, fullPath) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,185 @@
+/*
+@Date: 2026/10/19 17:10
+@Author: yvanz
+@File : rbac
+*/
+
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yvanz/gin-tmpl/pkg/gormdb"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrDenied        = errors.New("permission denied")
+	ErrNotConfigured = errors.New("authorization is not configured")
+
+	_default atomic.Value
+)
+
+// Enforcer decides whether the roles of a subject are allowed to access a route or have a permission.
+// The policy is replaced as a whole by Load, the requests in flight see either the old or the new one.
+type Enforcer struct {
+	policy     atomic.Value
+	load       func(ctx context.Context) (Policy, error)
+	rolesClaim string
+	dryRun     int32
+}
+
+// NewEnforcer returns an Enforcer of c which denies everything until the policy is loaded
+func NewEnforcer(c Config) (*Enforcer, error) {
+	if errs := c.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	e := &Enforcer{rolesClaim: c.RolesClaim}
+	e.policy.Store(&compiled{})
+	e.SetDryRun(c.DryRun)
+
+	switch {
+	case c.File != "":
+		e.load = func(context.Context) (Policy, error) {
+			return loadFile(c.File)
+		}
+	case c.Table != "":
+		e.load = func(ctx context.Context) (Policy, error) {
+			return loadTable(ctx, c.Table)
+		}
+	}
+
+	return e, nil
+}
+
+// Init builds the default Enforcer of c and loads the policy
+func Init(ctx context.Context, c Config) error {
+	e, err := NewEnforcer(c)
+	if err != nil {
+		return err
+	}
+
+	if err = e.Load(ctx); err != nil {
+		return err
+	}
+
+	_default.Store(e)
+	return nil
+}
+
+// Default returns the Enforcer built by Init, or nil
+func Default() *Enforcer {
+	e, _ := _default.Load().(*Enforcer)
+	return e
+}
+
+func loadFile(file string) (p Policy, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return p, err
+	}
+
+	if err = yaml.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("parse %s failed: %s", file, err.Error())
+	}
+
+	return p, nil
+}
+
+func loadTable(ctx context.Context, table string) (Policy, error) {
+	db := gormdb.Cli(ctx)
+	if db == nil {
+		return Policy{}, gormdb.ErrClient
+	}
+
+	var rules []Rule
+	if err := db.Table(table).Find(&rules).Error; err != nil {
+		return Policy{}, err
+	}
+
+	return policyFromRules(rules)
+}
+
+// Load reloads the policy from the file or the table, the current policy is kept if it fails
+func (e *Enforcer) Load(ctx context.Context) error {
+	if e.load == nil {
+		return ErrNotConfigured
+	}
+
+	p, err := e.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	return e.SetPolicy(p)
+}
+
+// SetPolicy replaces the policy by p if it is valid
+func (e *Enforcer) SetPolicy(p Policy) error {
+	c, err := compile(p)
+	if err != nil {
+		return err
+	}
+
+	e.policy.Store(c)
+	return nil
+}
+
+// DryRun reports whether the denials should be logged only
+func (e *Enforcer) DryRun() bool {
+	return atomic.LoadInt32(&e.dryRun) == 1
+}
+
+func (e *Enforcer) SetDryRun(dryRun bool) {
+	var v int32
+	if dryRun {
+		v = 1
+	}
+	atomic.StoreInt32(&e.dryRun, v)
+}
+
+func (e *Enforcer) current() *compiled {
+	return e.policy.Load().(*compiled)
+}
+
+// Roles returns the roles bound to subject by the policy and the roles in the roles_claim of the token claims.
+// The claim could be a list of strings or a string separated by spaces.
+func (e *Enforcer) Roles(subject string, claims map[string]interface{}) []string {
+	var extra []string
+	switch roles := claims[e.rolesClaim].(type) {
+	case string:
+		extra = strings.Fields(roles)
+	case []interface{}:
+		for _, r := range roles {
+			if s, ok := r.(string); ok {
+				extra = append(extra, s)
+			}
+		}
+	}
+
+	return e.current().rolesOf(subject, extra)
+}
+
+// Authorize returns ErrDenied unless one of roles has a permission matching method and fullPath
+func (e *Enforcer) Authorize(roles []string, method, fullPath string) error {
+	if !e.current().allows(roles, method, fullPath) {
+		return fmt.Errorf("%w: %s %s", ErrDenied, method, fullPath)
+	}
+
+	return nil
+}
+
+// Require returns ErrDenied unless one of roles has permission
+func (e *Enforcer) Require(roles []string, permission string) error {
+	if !e.current().hasPermission(roles, permission) {
+		return fmt.Errorf("%w: %s is required", ErrDenied, permission)
+	}
+
+	return nil
+}
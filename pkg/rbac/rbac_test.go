@@ -0,0 +1,123 @@
+/*
+@Date: 2026/10/19 17:50
+@Author: yvanz
+@File : rbac_test
+*/
+
+package rbac
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/yvanz/gin-tmpl/pkg/gormdb"
+)
+
+const policyYAML = `
+permissions:
+  demo:read:
+    - GET /api/v1/demo/test
+    - GET /api/v1/demo/test/:id
+  demo:write:
+    - "* /api/v1/demo/test/**"
+roles:
+  viewer: [demo:read]
+  editor: [demo:read, demo:write]
+  admin: ["*"]
+subjects:
+  alice: [editor]
+  "*": [viewer]
+`
+
+func TestEnforcer(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rbac.yaml")
+	if err := os.WriteFile(file, []byte(policyYAML), 0o600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	e, err := NewEnforcer(Config{File: file, RolesClaim: "roles"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = e.Authorize([]string{"admin"}, "GET", "/api/v1/demo/test"); !errors.Is(err, ErrDenied) {
+		t.Errorf("everything should be denied before loading, got %v", err)
+	}
+	if err = e.Load(context.Background()); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	bob := e.Roles("bob", nil)
+	alice := e.Roles("alice", nil)
+	root := e.Roles("", map[string]interface{}{"roles": []interface{}{"admin"}})
+	for _, tc := range []struct {
+		roles   []string
+		method  string
+		path    string
+		allowed bool
+	}{
+		{bob, "GET", "/api/v1/demo/test/:id", true},
+		{bob, "DELETE", "/api/v1/demo/test/:ids", false},
+		{alice, "DELETE", "/api/v1/demo/test/:ids", true},
+		{alice, "POST", "/api/v1/demo/test", true},
+		{alice, "POST", "/api/v1/demo/testing", false},
+		{nil, "GET", "/api/v1/demo/test", false},
+		{root, "PATCH", "/api/v1/demo/test/:id", true},
+		{root, "GET", "/api/v2/undefined", false},
+	} {
+		if err = e.Authorize(tc.roles, tc.method, tc.path); (err == nil) != tc.allowed {
+			t.Errorf("%v %s %s: want allowed %v, got %v", tc.roles, tc.method, tc.path, tc.allowed, err)
+		}
+	}
+
+	if err = e.Require(bob, "demo:write"); !errors.Is(err, ErrDenied) {
+		t.Errorf("want %v, got %v", ErrDenied, err)
+	}
+	if err = e.Require(e.Roles("", map[string]interface{}{"roles": "viewer editor"}), "demo:write"); err != nil {
+		t.Error(err.Error())
+	}
+
+	// a broken policy is rejected and the current one is kept
+	if err = os.WriteFile(file, []byte("roles:\n  viewer: [demo:typo]\n"), 0o600); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = e.Load(context.Background()); err == nil {
+		t.Error("unknown permission should be rejected")
+	}
+	if err = e.Authorize(alice, "PUT", "/api/v1/demo/test/:id"); err != nil {
+		t.Errorf("policy should be kept: %v", err)
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	if err := (gormdb.DBConfig{WriteDBHost: "localhost"}).BuildMockClient(); err != nil {
+		t.Fatal(err.Error())
+	}
+	mock, err := gormdb.GetMock()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mock.ExpectQuery("SELECT \\* FROM `rbac_rules`").WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "name", "value"}).
+		AddRow(1, RulePermission, "demo:read", "GET /api/v1/demo/test").
+		AddRow(2, RuleRole, "viewer", "demo:read").
+		AddRow(3, RuleSubject, "bob", "viewer"))
+
+	e, err := NewEnforcer(Config{Table: "rbac_rules"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = e.Load(context.Background()); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err = e.Authorize(e.Roles("bob", nil), "GET", "/api/v1/demo/test"); err != nil {
+		t.Error(err.Error())
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err.Error())
+	}
+}
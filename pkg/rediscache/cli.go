@@ -7,6 +7,9 @@
 package rediscache
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/go-redis/redis/v8"
 )
 
@@ -15,3 +18,24 @@ var _rdb *redis.Client
 func GetCli() *redis.Client {
 	return _rdb
 }
+
+// Ping sends PING to the default client
+func Ping(ctx context.Context) error {
+	if _rdb == nil {
+		return fmt.Errorf("redis client is not initialized yet")
+	}
+
+	return _rdb.Ping(ctx).Err()
+}
+
+// Close closes the default client
+func Close() error {
+	if _rdb == nil {
+		return nil
+	}
+
+	err := _rdb.Close()
+	_rdb = nil
+
+	return err
+}
@@ -18,7 +18,7 @@ type (
 	Config struct {
 		Addr           string         `yaml:"host_and_port" env:"RedisHostAndPort" end-description:"redis host and port, seems like 127.0.0.1:6379" json:"addr,omitempty"`
 		Username       string         `yaml:"user_name" env:"RedisUsername" json:"username,omitempty"`
-		Password       string         `yaml:"password" env:"RedisPassword" json:"password,omitempty"`
+		Password       string         `yaml:"password" env:"RedisPassword" json:"password,omitempty" secret:"true"`
 		ServerType     string         `yaml:"server_type" env:"RedisServerType" env-default:"standalone" end-description:"redis type, support standalone/sentinel only" json:"server_type,omitempty"`
 		SentinelConfig sentinelConfig `yaml:"sentinel" json:"sentinel_config,omitempty"`
 		DB             int            `yaml:"db" env:"RedisDB" json:"db,omitempty"`
@@ -27,11 +27,39 @@ type (
 	sentinelConfig struct {
 		MasterName string   `yaml:"sentinel_master_name" env:"RedisSentinelMasterName" json:"master_name,omitempty"`
 		Username   string   `yaml:"sentinel_username" env:"RedisSentinelUsername" json:"username,omitempty"`
-		Password   string   `yaml:"sentinel_password" env:"RedisSentinelPassword" json:"password,omitempty"`
+		Password   string   `yaml:"sentinel_password" env:"RedisSentinelPassword" json:"password,omitempty" secret:"true"`
 		Addrs      []string `yaml:"sentinel_addrs" env:"RedisSentinelAddrs" json:"addrs,omitempty"`
 	}
 )
 
+// Validate returns all problems of the config
+func (c *Config) Validate() (errs []error) {
+	switch c.ServerType {
+	case "standalone":
+		if c.Addr == "" {
+			errs = append(errs, fmt.Errorf("host_and_port is required"))
+		}
+	case "sentinel":
+		if c.SentinelConfig.MasterName == "" {
+			errs = append(errs, fmt.Errorf("sentinel.sentinel_master_name is required"))
+		}
+		if len(c.SentinelConfig.Addrs) == 0 {
+			errs = append(errs, fmt.Errorf("sentinel.sentinel_addrs is required"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported server_type %q, only support standalone/sentinel", c.ServerType))
+	}
+
+	if c.DB < 0 {
+		errs = append(errs, fmt.Errorf("db must not be negative"))
+	}
+	if c.PoolSize < 0 {
+		errs = append(errs, fmt.Errorf("pool_size must not be negative"))
+	}
+
+	return errs
+}
+
 func (c *Config) NewRedisCli(ctx context.Context) error {
 	if _rdb != nil {
 		return nil
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/yvanz/gin-tmpl/pkg/gadget"
 )
 
 type RedisCrud struct {
@@ -19,8 +20,9 @@ type RedisCrud struct {
 	Rdb *redis.Client
 }
 
+// NewCRUD returns a BasicCrud whose commands end with the request if ctx is a gin.Context, see gadget.RequestContext
 func NewCRUD(ctx context.Context, cli *redis.Client) BasicCrud {
-	return &RedisCrud{Ctx: ctx, Rdb: cli}
+	return &RedisCrud{Ctx: gadget.RequestContext(ctx), Rdb: cli}
 }
 
 func (c *RedisCrud) Get(key string) (val string, err error) {
@@ -9,6 +9,7 @@ package tracer
 import (
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -21,12 +22,84 @@ var (
 	// 仅用于单例模式
 	_default opentracing.Tracer
 	_dCloser io.Closer
+	_sampler = &liveSampler{}
 )
 
 type Config struct {
-	LocalAgentHostPort  string `yaml:"local_agent_host_port" env:"TraceAgent" env-description:"host and port of jaeger agent" json:"local_agent_host_port,omitempty"`
-	BufferFlushInterval int    `yaml:"buffer_flush_interval" json:"buffer_flush_interval,omitempty"`
-	LogSpan             bool   `yaml:"log_span" env:"TraceLog" env-description:"enable record span or not" json:"log_span,omitempty"`
+	LocalAgentHostPort  string  `yaml:"local_agent_host_port" env:"TraceAgent" env-description:"host and port of jaeger agent" json:"local_agent_host_port,omitempty" reload:"restart"`
+	SamplerType         string  `yaml:"sampler_type" env:"TraceSamplerType" env-description:"sampler type: const/probabilistic/ratelimiting, sample all if empty" json:"sampler_type,omitempty"`
+	SamplerParam        float64 `yaml:"sampler_param" env:"TraceSamplerParam" env-description:"0 or 1 for const, rate for probabilistic, traces per second for ratelimiting" json:"sampler_param,omitempty"`
+	BufferFlushInterval int     `yaml:"buffer_flush_interval" json:"buffer_flush_interval,omitempty" reload:"restart"`
+	LogSpan             bool    `yaml:"log_span" env:"TraceLog" env-description:"enable record span or not" json:"log_span,omitempty" reload:"restart"`
+}
+
+// Validate returns all problems of the config
+func (c Config) Validate() (errs []error) {
+	if _, err := newSampler(c); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func newSampler(c Config) (jaeger.Sampler, error) {
+	switch c.SamplerType {
+	case "":
+		return jaeger.NewConstSampler(true), nil
+	case jaeger.SamplerTypeConst:
+		return jaeger.NewConstSampler(c.SamplerParam != 0), nil
+	case jaeger.SamplerTypeProbabilistic:
+		return jaeger.NewProbabilisticSampler(c.SamplerParam)
+	case jaeger.SamplerTypeRateLimiting:
+		if c.SamplerParam < 0 {
+			return nil, fmt.Errorf("sampler_param of ratelimiting must not be negative")
+		}
+
+		return jaeger.NewRateLimitingSampler(c.SamplerParam), nil
+	default:
+		return nil, fmt.Errorf("unsupported sampler_type %q, only support const/probabilistic/ratelimiting", c.SamplerType)
+	}
+}
+
+// liveSampler delegates to the sampler stored in it, so that sampling could be changed without a new tracer
+type liveSampler struct {
+	v atomic.Value
+}
+
+type samplerHolder struct {
+	jaeger.Sampler
+}
+
+func (l *liveSampler) get() jaeger.Sampler {
+	return l.v.Load().(samplerHolder).Sampler
+}
+
+func (l *liveSampler) IsSampled(id jaeger.TraceID, operation string) (bool, []jaeger.Tag) {
+	return l.get().IsSampled(id, operation)
+}
+
+func (l *liveSampler) Close() {
+	l.get().Close()
+}
+
+func (l *liveSampler) Equal(other jaeger.Sampler) bool {
+	return l == other
+}
+
+// SetSampler changes sampler_type and sampler_param of the tracer on the fly
+func SetSampler(c Config) error {
+	sampler, err := newSampler(c)
+	if err != nil {
+		return err
+	}
+
+	old, _ := _sampler.v.Load().(samplerHolder)
+	_sampler.v.Store(samplerHolder{sampler})
+	if old.Sampler != nil {
+		old.Close()
+	}
+
+	return nil
 }
 
 func NewJaegerTracer(serviceName string, c *Config, logg *logger.DemoLog) (tra opentracing.Tracer, closer io.Closer, err error) {
@@ -37,12 +110,12 @@ func NewJaegerTracer(serviceName string, c *Config, logg *logger.DemoLog) (tra o
 		return
 	}
 
+	if err = SetSampler(*c); err != nil {
+		return
+	}
+
 	cfg := config.Configuration{
 		ServiceName: serviceName,
-		Sampler: &config.SamplerConfig{
-			Type:  jaeger.SamplerTypeConst,
-			Param: 1,
-		},
 		Reporter: &config.ReporterConfig{
 			LogSpans:            c.LogSpan,
 			BufferFlushInterval: time.Duration(c.BufferFlushInterval) * time.Second,
@@ -56,7 +129,7 @@ func NewJaegerTracer(serviceName string, c *Config, logg *logger.DemoLog) (tra o
 		},
 	}
 
-	_default, _dCloser, err = cfg.NewTracer(config.Logger(logg))
+	_default, _dCloser, err = cfg.NewTracer(config.Logger(logg), config.Sampler(_sampler))
 	if err != nil {
 		return
 	}